@@ -0,0 +1,81 @@
+package core
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+func parseExtraCurrency(entry string) utils.Currency {
+	code, name, _ := strings.Cut(strings.TrimSpace(entry), ":")
+	return utils.Currency{Code: strings.ToUpper(strings.TrimSpace(code)), Name: strings.TrimSpace(name)}
+}
+
+// BuildCurrencyCatalogue restricts the provider's list to allowlist (when set)
+// and appends extras given as "CODE" or "CODE:Name". The default home currency
+// is always kept so chats without a setting keep working.
+func BuildCurrencyCatalogue(provided []schemas.CurrencyInfo, allowlist, extras []string) []utils.Currency {
+	allowed := make(map[string]bool, len(allowlist))
+	for _, code := range allowlist {
+		allowed[strings.ToUpper(strings.TrimSpace(code))] = true
+	}
+
+	catalogue := make([]utils.Currency, 0, len(provided)+len(extras)+1)
+	seen := make(map[string]bool, len(provided))
+	add := func(c utils.Currency) {
+		if c.Code == "" || seen[c.Code] {
+			return
+		}
+		seen[c.Code] = true
+		catalogue = append(catalogue, c)
+	}
+
+	for _, c := range provided {
+		code := strings.ToUpper(c.Code)
+		if len(allowed) > 0 && !allowed[code] {
+			continue
+		}
+		add(utils.Currency{Code: code, Name: c.Name})
+	}
+	for _, entry := range extras {
+		add(parseExtraCurrency(entry))
+	}
+	if !seen[utils.DEFAULT_BASE_CURRENCY] {
+		add(utils.Currency{Code: utils.DEFAULT_BASE_CURRENCY, Name: "Singapore Dollar"})
+	}
+
+	for code := range allowed {
+		if !seen[code] {
+			log.Warnf("Allowlisted currency %s is not offered by %s", code, ActiveRateProvider.Name())
+		}
+	}
+	return catalogue
+}
+
+func RefreshCurrencyCatalogue() error {
+	provided, err := ActiveRateProvider.SupportedCurrencies()
+	if err != nil {
+		return err
+	}
+	catalogue := BuildCurrencyCatalogue(provided, utils.CurrencyAllowlist, utils.CurrencyExtras)
+	utils.SetSupportedCurrencies(catalogue)
+	log.Infof("Loaded %d supported currencies from %s", len(catalogue), ActiveRateProvider.Name())
+	return nil
+}
+
+func StartCurrencyRefresher(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := RefreshCurrencyCatalogue(); err != nil {
+			log.Errorf("Error refreshing supported currencies, keeping previous list: %v", err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCurrencyCatalogue_AllowlistAndExtras(t *testing.T) {
+	provided := []schemas.CurrencyInfo{
+		{Code: "USD", Name: "United States Dollar"},
+		{Code: "EUR", Name: "Euro"},
+		{Code: "CHF", Name: "Swiss Franc"},
+	}
+
+	catalogue := BuildCurrencyCatalogue(provided, nil, nil)
+	assert.Equal(t, []utils.Currency{
+		{Code: "USD", Name: "United States Dollar"},
+		{Code: "EUR", Name: "Euro"},
+		{Code: "CHF", Name: "Swiss Franc"},
+		{Code: "SGD", Name: "Singapore Dollar"},
+	}, catalogue)
+
+	catalogue = BuildCurrencyCatalogue(provided, []string{"usd", " EUR"}, []string{"VND:Vietnamese Dong", "USD:Ignored"})
+	assert.Equal(t, []utils.Currency{
+		{Code: "USD", Name: "United States Dollar"},
+		{Code: "EUR", Name: "Euro"},
+		{Code: "VND", Name: "Vietnamese Dong"},
+		{Code: "SGD", Name: "Singapore Dollar"},
+	}, catalogue)
+}
+
+func TestRefreshCurrencyCatalogue(t *testing.T) {
+	original := utils.SupportedCurrencies()
+	t.Cleanup(func() { utils.SetSupportedCurrencies(original) })
+
+	useRateProvider(t, NewFallbackProvider(
+		&stubRateProvider{name: "Primary", rates: map[string]float64{"USD": 1.35}},
+		&stubRateProvider{name: "Backup", rates: map[string]float64{"TWD": 0.042}},
+	))
+	require.NoError(t, RefreshCurrencyCatalogue())
+	assert.Equal(t, []string{"SGD", "TWD", "USD"}, utils.SupportedCurrencyCodes())
+
+	useRateProvider(t, &stubRateProvider{err: errors.New("offline")})
+	assert.Error(t, RefreshCurrencyCatalogue())
+	assert.Equal(t, []string{"SGD", "TWD", "USD"}, utils.SupportedCurrencyCodes())
+}
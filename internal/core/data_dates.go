@@ -0,0 +1,100 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+)
+
+// dataDateTracker remembers the data date last evaluated for each pair and
+// the version of each alert last evaluated, so alerts only run when a
+// provider has published something new or the alert itself has changed.
+type dataDateTracker struct {
+	mu       sync.Mutex
+	lastSeen map[string]string
+	versions map[string]int
+	nextDue  time.Time
+}
+
+func newDataDateTracker() *dataDateTracker {
+	return &dataDateTracker{lastSeen: make(map[string]string), versions: make(map[string]int)}
+}
+
+var rateDataDates = newDataDateTracker()
+
+func (t *dataDateTracker) Due(now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !now.Before(t.nextDue)
+}
+
+func (t *dataDateTracker) NextDue() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.nextDue
+}
+
+func (t *dataDateTracker) HasUnseen(pairs []string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, pair := range pairs {
+		if _, ok := t.lastSeen[pair]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *dataDateTracker) IsNew(pair, date string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return date > t.lastSeen[pair]
+}
+
+func (t *dataDateTracker) MarkSeen(pair, date string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if date > t.lastSeen[pair] {
+		t.lastSeen[pair] = date
+	}
+}
+
+// Changed reports whether sub was created or edited since it was last
+// evaluated, in which case it is evaluated even on a data date already seen.
+func (t *dataDateTracker) Changed(sub schemas.CurrencySubscription) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	version, ok := t.versions[sub.ID]
+	return !ok || version != sub.Version
+}
+
+func (t *dataDateTracker) Evaluated(sub schemas.CurrencySubscription) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.versions[sub.ID] = sub.Version
+}
+
+// Forget makes the alert count as changed, so that the next run evaluates it
+// again.
+func (t *dataDateTracker) Forget(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.versions, id)
+}
+
+// Schedule sets the next due time to the publication following the oldest
+// data date just seen. If that publication is already past (the provider is
+// late), runs stay due every tick until new data shows up.
+func (t *dataDateTracker) Schedule(provider schemas.RateProvider, oldestDate string) {
+	var nextDue time.Time
+	if date, err := time.Parse("2006-01-02", oldestDate); err == nil {
+		if next, ok := schemas.NextPublicationOf(provider, date.AddDate(0, 0, 1)); ok {
+			nextDue = next
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextDue = nextDue
+}
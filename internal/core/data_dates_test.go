@@ -0,0 +1,83 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataDateTracker_OnlyNewDatesAreNew(t *testing.T) {
+	tracker := newDataDateTracker()
+
+	assert.True(t, tracker.HasUnseen([]string{"USD/SGD"}))
+	assert.True(t, tracker.IsNew("USD/SGD", "2026-02-20"))
+
+	tracker.MarkSeen("USD/SGD", "2026-02-20")
+	assert.False(t, tracker.HasUnseen([]string{"USD/SGD"}))
+	assert.False(t, tracker.IsNew("USD/SGD", "2026-02-20"))
+	assert.False(t, tracker.IsNew("USD/SGD", "2026-02-19"))
+	assert.True(t, tracker.IsNew("USD/SGD", "2026-02-23"))
+	assert.True(t, tracker.HasUnseen([]string{"USD/SGD", "EUR/SGD"}))
+}
+
+func TestDataDateTracker_SchedulesNextPublication(t *testing.T) {
+	tracker := newDataDateTracker()
+	frankfurter := schemas.NewFrankfurterProvider("")
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	tracker.Schedule(frankfurter, "2026-04-02")
+	expected := time.Date(2026, 4, 7, 16, 30, 0, 0, berlin)
+	assert.True(t, expected.Equal(tracker.NextDue()), "got %v", tracker.NextDue())
+	assert.False(t, tracker.Due(time.Date(2026, 4, 6, 12, 0, 0, 0, berlin)))
+	assert.True(t, tracker.Due(expected))
+
+	// a late provider keeps the scheduler due on every tick
+	assert.True(t, tracker.Due(expected.Add(3*time.Hour)))
+
+	tracker.Schedule(&stubRateProvider{}, "2026-04-02")
+	assert.True(t, tracker.Due(time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestDecoratorsForwardNextPublication(t *testing.T) {
+	after := time.Date(2026, 2, 20, 18, 0, 0, 0, time.UTC)
+	frankfurter := schemas.NewFrankfurterProvider("")
+	mas := schemas.NewMASProvider("", "")
+	expected := frankfurter.NextPublication(after)
+
+	provider, err := BuildRateProvider(RateProviderOptions{
+		Providers:  []string{"frankfurter"},
+		CrossCheck: "mas",
+		Pivots:     []string{"SGD"},
+		CacheTTL:   time.Hour,
+		History:    store.NewMemoryRateHistory(),
+	})
+	assert.NoError(t, err)
+	next, ok := schemas.NextPublicationOf(provider, after)
+	assert.True(t, ok)
+	assert.True(t, expected.Equal(next))
+
+	next, ok = schemas.NextPublicationOf(NewFallbackProvider(frankfurter, mas), after)
+	assert.True(t, ok)
+	assert.True(t, mas.NextPublication(after).Equal(next))
+
+	_, ok = schemas.NextPublicationOf(NewCachedProvider(&stubRateProvider{}, time.Hour), after)
+	assert.False(t, ok)
+}
+
+func TestDataDateTracker_ChangedAlerts(t *testing.T) {
+	tracker := newDataDateTracker()
+	sub := schemas.CurrencySubscription{ID: "sub-1", Version: 2}
+
+	assert.True(t, tracker.Changed(sub))
+	tracker.Evaluated(sub)
+	assert.False(t, tracker.Changed(sub))
+
+	sub.Version++
+	assert.True(t, tracker.Changed(sub))
+	tracker.Evaluated(sub)
+	tracker.Forget(sub.ID)
+	assert.True(t, tracker.Changed(sub))
+}
@@ -1,20 +1,117 @@
 package core
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
 )
 
-func GetCurrentRate(currency string) (float64, *schemas.FrankfurterLatestResponse, error) {
-	return schemas.FetchLatestExchangeRate(currency)
+var ActiveRateProvider schemas.RateProvider = schemas.NewFrankfurterProvider(schemas.FrankfurterAPIURL)
+
+var RateHistory schemas.RateHistoryStore
+
+func NewRateProvider(name string) (schemas.RateProvider, error) {
+	switch strings.ToLower(name) {
+	case "", "frankfurter":
+		return schemas.NewFrankfurterProvider(utils.FrankfurterAPIURL), nil
+	case "mas":
+		return schemas.NewMASProvider(utils.MASAPIURL, utils.MASResourceID), nil
+	default:
+		return nil, fmt.Errorf("unknown rate provider: %s", name)
+	}
+}
+
+type RateProviderOptions struct {
+	Providers        []string
+	CrossCheck       string
+	TolerancePercent float64
+	Pivots           []string
+	CacheTTL         time.Duration
+	History          schemas.RateHistoryStore
 }
 
-func GetHistoricalRates(currency string, months int) ([]schemas.HistoricalRate, error) {
+func BuildRateProvider(opts RateProviderOptions) (schemas.RateProvider, error) {
+	names := opts.Providers
+	if len(names) == 0 {
+		names = []string{"frankfurter"}
+	}
+
+	newProvider := func(name string) (schemas.RateProvider, error) {
+		provider, err := NewRateProvider(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		if opts.CacheTTL > 0 {
+			return NewCachedProvider(provider, opts.CacheTTL), nil
+		}
+		return provider, nil
+	}
+
+	providers := make([]schemas.RateProvider, 0, len(names))
+	for _, name := range names {
+		provider, err := newProvider(name)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	if opts.CrossCheck != "" {
+		reference, err := newProvider(opts.CrossCheck)
+		if err != nil {
+			return nil, err
+		}
+		providers = crossCheckEach(providers, reference, opts.TolerancePercent)
+	}
+
+	var provider schemas.RateProvider = providers[0]
+	if len(providers) > 1 {
+		provider = NewFallbackProvider(providers...)
+	}
+
+	if opts.History != nil {
+		provider = NewHistoryBackedProvider(provider, opts.History)
+	}
+
+	if len(opts.Pivots) > 0 {
+		provider = NewTriangulatingProvider(provider, opts.Pivots)
+	}
+
+	return provider, nil
+}
+
+func GetCurrentRate(base, currency string) (float64, *schemas.ExchangeRate, error) {
+	quote, err := schemas.FetchLatestRate(ActiveRateProvider, base, currency)
+	if err != nil {
+		return 0, nil, err
+	}
+	return quote.Rate, quote, nil
+}
+
+func GetCurrentRates(base string, currencies []string) (map[string]*schemas.ExchangeRate, error) {
+	return ActiveRateProvider.LatestRates(base, currencies)
+}
+
+func historicalRange(months int) (time.Time, time.Time) {
 	if months <= 0 {
 		months = 12
 	}
 	if months > 120 {
 		months = 120
 	}
-	days := months * 30
-	return schemas.FetchHistoricalExchangeRates(currency, days)
+	end := time.Now()
+	return end.AddDate(0, 0, -months*30), end
+}
+
+func GetHistoricalRates(base, currency string, months int) ([]schemas.HistoricalRate, error) {
+	start, end := historicalRange(months)
+	return schemas.FetchHistoricalRates(ActiveRateProvider, base, currency, start, end)
+}
+
+func GetHistoricalRatesForCurrencies(base string, currencies []string, months int) (map[string][]schemas.HistoricalRate, error) {
+	start, end := historicalRange(months)
+	return ActiveRateProvider.HistoricalRates(base, currencies, start, end)
 }
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubRateProvider struct {
+	name    string
+	err     error
+	rates   map[string]float64
+	history []schemas.HistoricalRate
+	start   time.Time
+	end     time.Time
+}
+
+func (p *stubRateProvider) Name() string {
+	if p.name != "" {
+		return p.name
+	}
+	return "Stub"
+}
+
+func (p *stubRateProvider) LatestRates(base string, currencies []string) (map[string]*schemas.ExchangeRate, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	quotes := make(map[string]*schemas.ExchangeRate)
+	for _, currency := range currencies {
+		if rate, ok := p.rates[currency]; ok {
+			quotes[currency] = &schemas.ExchangeRate{Base: base, Currency: currency, Rate: rate, Date: "2026-02-20", Source: p.Name()}
+		}
+	}
+	return quotes, nil
+}
+
+func (p *stubRateProvider) HistoricalRates(base string, currencies []string, start, end time.Time) (map[string][]schemas.HistoricalRate, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.start, p.end = start, end
+	histories := make(map[string][]schemas.HistoricalRate)
+	for _, currency := range currencies {
+		histories[currency] = p.history
+	}
+	return histories, nil
+}
+
+func (p *stubRateProvider) SupportedCurrencies() ([]schemas.CurrencyInfo, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	currencies := make([]schemas.CurrencyInfo, 0, len(p.rates))
+	for code := range p.rates {
+		currencies = append(currencies, schemas.CurrencyInfo{Code: code})
+	}
+	return currencies, nil
+}
+
+func useRateProvider(t *testing.T, provider schemas.RateProvider) {
+	original := ActiveRateProvider
+	ActiveRateProvider = provider
+	t.Cleanup(func() { ActiveRateProvider = original })
+}
+
+func TestNewRateProvider(t *testing.T) {
+	provider, err := NewRateProvider("Frankfurter")
+	require.NoError(t, err)
+	assert.Equal(t, "Frankfurter", provider.Name())
+
+	provider, err = NewRateProvider("mas")
+	require.NoError(t, err)
+	assert.Equal(t, "MAS", provider.Name())
+
+	_, err = NewRateProvider("unknown")
+	assert.Error(t, err)
+}
+
+func TestGetCurrentRate_UsesActiveProvider(t *testing.T) {
+	useRateProvider(t, &stubRateProvider{rates: map[string]float64{"USD": 1.35}})
+
+	rate, quote, err := GetCurrentRate("SGD", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, 1.35, rate)
+	assert.Equal(t, "Stub", quote.Source)
+
+	_, _, err = GetCurrentRate("SGD", "EUR")
+	assert.Error(t, err)
+}
+
+func TestGetHistoricalRates_ClampsMonths(t *testing.T) {
+	provider := &stubRateProvider{}
+	useRateProvider(t, provider)
+
+	_, err := GetHistoricalRates("SGD", "USD", 500)
+	require.NoError(t, err)
+	assert.InDelta(t, 120*30, provider.end.Sub(provider.start).Hours()/24, 1)
+
+	_, err = GetHistoricalRates("SGD", "USD", 0)
+	require.NoError(t, err)
+	assert.InDelta(t, 12*30, provider.end.Sub(provider.start).Hours()/24, 1)
+}
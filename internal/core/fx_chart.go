@@ -3,12 +3,13 @@ package core
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
 	"github.com/vicanso/go-charts/v2"
 )
 
-func GenerateExchangeRateChart(rates []schemas.HistoricalRate, currency string) (*[]byte, error) {
+func GenerateExchangeRateChart(rates []schemas.HistoricalRate, base, currency string) (*[]byte, error) {
 	if len(rates) == 0 {
 		return nil, fmt.Errorf("no historical rates available")
 	}
@@ -48,7 +49,7 @@ func GenerateExchangeRateChart(rates []schemas.HistoricalRate, currency string)
 			},
 		},
 		Title: charts.TitleOption{
-			Text: fmt.Sprintf("%s/SGD Exchange Rate History", currency),
+			Text: fmt.Sprintf("%s/%s Exchange Rate History", currency, base),
 		},
 		Padding: charts.Box{
 			Top:    20,
@@ -84,23 +85,41 @@ func GenerateExchangeRateChart(rates []schemas.HistoricalRate, currency string)
 	return &buf, nil
 }
 
-func FormatCurrentRateMessage(currency string, rate float64, response *schemas.FrankfurterLatestResponse) string {
+func FormatCurrentRateMessage(base, currency string, rate float64, quote *schemas.ExchangeRate) string {
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("💱 %s/SGD Exchange Rate\n\n", currency))
-	sb.WriteString(fmt.Sprintf("1 %s → %.4f SGD\n", currency, rate))
-	sb.WriteString(fmt.Sprintf("1 SGD → %.4f %s\n\n", 1/rate, currency))
-
-	if response != nil {
-		sb.WriteString(fmt.Sprintf("Data as of: %s\n", response.Date))
+	sb.WriteString(fmt.Sprintf("💱 %s/%s Exchange Rate\n\n", currency, base))
+	sb.WriteString(fmt.Sprintf("1 %s → %.4f %s\n", currency, rate, base))
+	sb.WriteString(fmt.Sprintf("1 %s → %.4f %s\n\n", base, 1/rate, currency))
+
+	if quote != nil {
+		sb.WriteString(fmt.Sprintf("Data as of: %s\n", quote.Date))
+		if quote.Via != "" {
+			sb.WriteString(fmt.Sprintf("Source: %s (cross rate via %s)\n", quote.Source, quote.Via))
+		} else if quote.Source != "" {
+			sb.WriteString(fmt.Sprintf("Source: %s\n", quote.Source))
+		}
+		sb.WriteString(FormatCrossCheckWarning(quote))
 	}
 
 	sb.WriteString("\nUse /fx_chart ")
 	sb.WriteString(currency)
+	sb.WriteString(" ")
+	sb.WriteString(base)
 	sb.WriteString(" for historical chart")
 
 	return sb.String()
 }
 
+func FormatDataDate(quote *schemas.ExchangeRate) string {
+	if quote == nil || quote.Date == "" {
+		return ""
+	}
+	if quote.Source == "" {
+		return fmt.Sprintf("📅 Data as of: %s\n", quote.Date)
+	}
+	return fmt.Sprintf("📅 Data as of: %s (%s)\n", quote.Date, quote.Source)
+}
+
 func FormatSubscriptionListMessage(subscriptions []schemas.CurrencySubscription) string {
 	if len(subscriptions) == 0 {
 		return "You have no active subscriptions.\n\nUse /fx_subscribe to create one."
@@ -109,20 +128,116 @@ func FormatSubscriptionListMessage(subscriptions []schemas.CurrencySubscription)
 	var sb strings.Builder
 	sb.WriteString("📋 Your Currency Subscriptions\n\n")
 
+	var pairs []string
+	byPair := make(map[string][]schemas.CurrencySubscription)
 	for _, sub := range subscriptions {
-		sb.WriteString(fmt.Sprintf("💱 %s/SGD\n", sub.Currency))
-		if sub.ThresholdAbove != nil {
-			sb.WriteString(fmt.Sprintf("  • Alert above: %.4f SGD (1 SGD → %.4f %s)\n", *sub.ThresholdAbove, 1.0/(*sub.ThresholdAbove), sub.Currency))
+		if _, seen := byPair[sub.Pair()]; !seen {
+			pairs = append(pairs, sub.Pair())
 		}
-		if sub.ThresholdBelow != nil {
-			sb.WriteString(fmt.Sprintf("  • Alert below: %.4f SGD (1 SGD → %.4f %s)\n", *sub.ThresholdBelow, 1.0/(*sub.ThresholdBelow), sub.Currency))
-		}
-		if sub.Interval != nil {
-			sb.WriteString(fmt.Sprintf("  • Interval: %.4f SGD (1 SGD → %.4f %s)\n", *sub.Interval, 1.0/(*sub.Interval), sub.Currency))
+		byPair[sub.Pair()] = append(byPair[sub.Pair()], sub)
+	}
+
+	for _, pair := range pairs {
+		sb.WriteString(fmt.Sprintf("💱 %s\n", pair))
+		for _, sub := range byPair[pair] {
+			base := sub.Base()
+			prefix := "  •"
+			if sub.ID != "" {
+				prefix = fmt.Sprintf("  • [%s]", sub.ShortID())
+			}
+			if level := sub.AboveLevel(); level != nil {
+				sb.WriteString(fmt.Sprintf("%s Alert above: %s%.4f %s (1 %s → %.4f %s)%s\n", prefix, formatPercentOf(sub.ThresholdAbovePercent, sub.ReferenceRate), *level, base, base, 1.0/(*level), sub.Currency, formatRepeat(sub, schemas.TriggerAbove)))
+			}
+			if level := sub.BelowLevel(); level != nil {
+				sb.WriteString(fmt.Sprintf("%s Alert below: %s%.4f %s (1 %s → %.4f %s)%s\n", prefix, formatPercentOf(sub.ThresholdBelowPercent, sub.ReferenceRate), *level, base, base, 1.0/(*level), sub.Currency, formatRepeat(sub, schemas.TriggerBelow)))
+			}
+			if sub.IntervalPercent != nil {
+				line := fmt.Sprintf("%s Interval: %.2f%%", prefix, *sub.IntervalPercent)
+				if step := sub.IntervalStep(); step != nil {
+					line += fmt.Sprintf(" (currently %.4f %s from %.4f)", *step, base, sub.LastNotifiedRate)
+				}
+				sb.WriteString(line + "\n")
+			} else if sub.Interval != nil {
+				sb.WriteString(fmt.Sprintf("%s Interval: %.4f %s (1 %s → %.4f %s)\n", prefix, *sub.Interval, base, base, 1.0/(*sub.Interval), sub.Currency))
+			}
+			if n := len(sub.Ladder); n > 0 {
+				bound := "up to"
+				if sub.LadderDirection == schemas.TriggerBelow {
+					bound = "down to"
+				}
+				sb.WriteString(fmt.Sprintf("%s Ladder %s: next %.4f %s, %d rungs left %s %.4f %s\n", prefix, sub.LadderDirection, sub.Ladder[0], base, n, bound, sub.Ladder[n-1], base))
+			}
+			if sub.Indicator != nil {
+				line := fmt.Sprintf("%s Indicator: %s", prefix, sub.Indicator.Describe())
+				if sub.Indicator.LastSignal != "" {
+					line += fmt.Sprintf(" (last signal %s)", sub.Indicator.LastSignal)
+				}
+				sb.WriteString(line + "\n")
+			}
 		}
 		sb.WriteString("\n")
 	}
 
-	sb.WriteString("Use /fx_unsubscribe <currency> to remove a subscription.")
+	sb.WriteString("Use /fx_unsubscribe <id> to remove an alert, or /fx_unsubscribe <currency> to remove every alert for a pair.")
+	return sb.String()
+}
+
+// formatPercentOf describes a percentage threshold and the rate it is
+// relative to, e.g. "+2.00% of 1.3500 → ".
+func formatPercentOf(percent *float64, reference float64) string {
+	if percent == nil {
+		return ""
+	}
+	return fmt.Sprintf("%+.2f%% of %.4f → ", *percent, reference)
+}
+
+// formatRepeat describes when a repeating threshold re-arms, and whether it
+// is waiting to.
+func formatRepeat(sub schemas.CurrencySubscription, trigger string) string {
+	rearm := sub.RearmLevel(trigger)
+	if !sub.Repeat || rearm == nil {
+		return ""
+	}
+	direction := "below"
+	if trigger == schemas.TriggerBelow {
+		direction = "above"
+	}
+	if sub.Triggered == trigger {
+		return fmt.Sprintf(" 🔁 fired, re-arms %s %.4f", direction, *rearm)
+	}
+	return fmt.Sprintf(" 🔁 repeats, re-arms %s %.4f", direction, *rearm)
+}
+
+var triggerDescriptions = map[string]string{
+	schemas.TriggerAbove:     "rose above threshold",
+	schemas.TriggerBelow:     "fell below threshold",
+	schemas.TriggerInterval:  "moved by interval",
+	schemas.TriggerLadder:    "crossed a ladder rung",
+	schemas.TriggerIndicator: "gave an indicator signal",
+}
+
+func FormatNotificationHistoryMessage(notifications []schemas.Notification, timezone *time.Location) string {
+	if len(notifications) == 0 {
+		return "No alerts have been sent to this chat yet."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🔔 Recent Alerts\n\n")
+	for _, n := range notifications {
+		trigger, ok := triggerDescriptions[n.Trigger]
+		if !ok {
+			trigger = n.Trigger
+		}
+		sb.WriteString(fmt.Sprintf("%s %s %s at %.4f %s",
+			n.SentAt.In(timezone).Format("2006-01-02 15:04"), n.Pair(), trigger, n.Rate, n.BaseCurrency))
+		if n.DataDate != "" {
+			sb.WriteString(fmt.Sprintf(" (data %s)", n.DataDate))
+		}
+		if n.Delivered() {
+			sb.WriteString(" ✅\n")
+		} else {
+			sb.WriteString(fmt.Sprintf(" ⚠️ not delivered: %s\n", n.Error))
+		}
+	}
 	return sb.String()
 }
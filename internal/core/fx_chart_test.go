@@ -1,6 +1,7 @@
 package core
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -18,7 +19,7 @@ func TestGenerateExchangeRateChart_Success(t *testing.T) {
 		{Date: time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC), Rate: 1.3100},
 	}
 
-	chartData, err := GenerateExchangeRateChart(rates, "USD")
+	chartData, err := GenerateExchangeRateChart(rates, "SGD", "USD")
 	require.NoError(t, err)
 	require.NotNil(t, chartData)
 	assert.NotEmpty(t, *chartData)
@@ -26,7 +27,7 @@ func TestGenerateExchangeRateChart_Success(t *testing.T) {
 }
 
 func TestGenerateExchangeRateChart_EmptyRates(t *testing.T) {
-	_, err := GenerateExchangeRateChart([]schemas.HistoricalRate{}, "USD")
+	_, err := GenerateExchangeRateChart([]schemas.HistoricalRate{}, "SGD", "USD")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no historical rates")
 }
@@ -36,7 +37,7 @@ func TestGenerateExchangeRateChart_SingleRate(t *testing.T) {
 		{Date: time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC), Rate: 1.3500},
 	}
 
-	chartData, err := GenerateExchangeRateChart(rates, "USD")
+	chartData, err := GenerateExchangeRateChart(rates, "SGD", "USD")
 	require.NoError(t, err)
 	require.NotNil(t, chartData)
 	assert.NotEmpty(t, *chartData)
@@ -49,35 +50,45 @@ func TestGenerateExchangeRateChart_YAxisScaling(t *testing.T) {
 		{Date: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), Rate: 1.3499},
 	}
 
-	chartData, err := GenerateExchangeRateChart(rates, "USD")
+	chartData, err := GenerateExchangeRateChart(rates, "SGD", "USD")
 	require.NoError(t, err)
 	require.NotNil(t, chartData)
 	assert.NotEmpty(t, *chartData)
 }
 
 func TestFormatCurrentRateMessage(t *testing.T) {
-	response := &schemas.FrankfurterLatestResponse{
-		Amount: 1.0,
-		Base:   "SGD",
-		Date:   "2026-02-20",
-		Rates:  map[string]float64{"USD": 1.2678},
+	quote := &schemas.ExchangeRate{
+		Base:     "SGD",
+		Currency: "USD",
+		Rate:     0.7889,
+		Date:     "2026-02-20",
+		Source:   "Frankfurter",
 	}
 
-	msg := FormatCurrentRateMessage("USD", 0.7889, response)
+	msg := FormatCurrentRateMessage("SGD", "USD", 0.7889, quote)
 
 	assert.Contains(t, msg, "USD/SGD Exchange Rate")
 	assert.Contains(t, msg, "0.7889")
 	assert.Contains(t, msg, "2026-02-20")
+	assert.Contains(t, msg, "Source: Frankfurter")
 	assert.Contains(t, msg, "/fx_chart USD")
 }
 
 func TestFormatCurrentRateMessage_NilResponse(t *testing.T) {
-	msg := FormatCurrentRateMessage("EUR", 1.4500, nil)
+	msg := FormatCurrentRateMessage("SGD", "EUR", 1.4500, nil)
 
 	assert.Contains(t, msg, "EUR/SGD Exchange Rate")
 	assert.Contains(t, msg, "1.4500")
 }
 
+func TestFormatCurrentRateMessage_HomeCurrency(t *testing.T) {
+	msg := FormatCurrentRateMessage("MYR", "USD", 4.4700, nil)
+
+	assert.Contains(t, msg, "USD/MYR Exchange Rate")
+	assert.Contains(t, msg, "1 USD → 4.4700 MYR")
+	assert.NotContains(t, msg, "SGD")
+}
+
 func TestFormatSubscriptionListMessage_Empty(t *testing.T) {
 	msg := FormatSubscriptionListMessage(nil)
 	assert.Contains(t, msg, "no active subscriptions")
@@ -107,6 +118,43 @@ func TestFormatSubscriptionListMessage_WithSubscriptions(t *testing.T) {
 	assert.Contains(t, msg, "Alert above")
 	assert.Contains(t, msg, "Interval")
 	assert.Contains(t, msg, "Alert below")
+	assert.Contains(t, msg, "USD/SGD")
+}
+
+func TestFormatSubscriptionListMessage_HomeCurrency(t *testing.T) {
+	subscriptions := []schemas.CurrencySubscription{
+		{
+			BaseCurrency:   "MYR",
+			Currency:       "USD",
+			ThresholdAbove: float64Ptr(4.5000),
+		},
+	}
+
+	msg := FormatSubscriptionListMessage(subscriptions)
+
+	assert.Contains(t, msg, "USD/MYR")
+	assert.Contains(t, msg, "Alert above: 4.5000 MYR")
+}
+
+func TestFormatSubscriptionListMessage_GroupsAlertsByPair(t *testing.T) {
+	subscriptions := []schemas.CurrencySubscription{
+		{ID: "1a2b3c4d-0000", Currency: "USD", ThresholdAbove: float64Ptr(1.40)},
+		{ID: "9f8e7d6c-0000", Currency: "EUR", Interval: float64Ptr(0.05)},
+		{ID: "5a6b7c8d-0000", Currency: "USD", ThresholdAbove: float64Ptr(1.45)},
+	}
+
+	msg := FormatSubscriptionListMessage(subscriptions)
+
+	assert.Equal(t, 1, strings.Count(msg, "💱 USD/SGD"))
+	assert.Contains(t, msg, "[1a2b3c4d] Alert above: 1.4000 SGD")
+	assert.Contains(t, msg, "[5a6b7c8d] Alert above: 1.4500 SGD")
+	assert.Less(t, strings.Index(msg, "[5a6b7c8d]"), strings.Index(msg, "💱 EUR/SGD"))
+}
+
+func TestFormatDataDate(t *testing.T) {
+	assert.Equal(t, "📅 Data as of: 2026-02-20 (MAS)\n", FormatDataDate(&schemas.ExchangeRate{Date: "2026-02-20", Source: "MAS"}))
+	assert.Equal(t, "📅 Data as of: 2026-02-20\n", FormatDataDate(&schemas.ExchangeRate{Date: "2026-02-20"}))
+	assert.Empty(t, FormatDataDate(nil))
 }
 
 func float64Ptr(f float64) *float64 {
@@ -0,0 +1,190 @@
+package indicators
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+)
+
+// MaxPeriod keeps indicator periods within the 12 months of daily history the
+// scheduler loads.
+const MaxPeriod = 200
+
+const Usage = "sma|ema <fast> <slow> above|below\n" +
+	"rsi <period> above|below <level>\n" +
+	"bollinger <period> <deviations> [above|below]"
+
+// IsKind reports whether s names an indicator ParseAlert accepts.
+func IsKind(s string) bool {
+	switch strings.ToLower(s) {
+	case schemas.IndicatorSMA, schemas.IndicatorEMA, schemas.IndicatorRSI, schemas.IndicatorBollinger:
+		return true
+	}
+	return false
+}
+
+// ParseAlert reads an indicator alert from the arguments of /fx_indicator that
+// follow the currency pair, in one of the forms listed in Usage.
+func ParseAlert(args []string) (schemas.IndicatorAlert, error) {
+	if len(args) == 0 {
+		return schemas.IndicatorAlert{}, errors.New("missing indicator")
+	}
+	a := schemas.IndicatorAlert{Kind: strings.ToLower(args[0])}
+	args = args[1:]
+
+	var err error
+	switch a.Kind {
+	case schemas.IndicatorSMA, schemas.IndicatorEMA:
+		if len(args) != 3 {
+			return a, fmt.Errorf("expected %s <fast> <slow> above|below", a.Kind)
+		}
+		if a.Fast, err = parsePeriod(args[0]); err != nil {
+			return a, err
+		}
+		if a.Slow, err = parsePeriod(args[1]); err != nil {
+			return a, err
+		}
+		a.Direction = strings.ToLower(args[2])
+	case schemas.IndicatorRSI:
+		if len(args) != 3 {
+			return a, errors.New("expected rsi <period> above|below <level>")
+		}
+		if a.Period, err = parsePeriod(args[0]); err != nil {
+			return a, err
+		}
+		a.Direction = strings.ToLower(args[1])
+		if a.Level, err = strconv.ParseFloat(args[2], 64); err != nil {
+			return a, fmt.Errorf("%q is not a number", args[2])
+		}
+	case schemas.IndicatorBollinger:
+		if len(args) != 2 && len(args) != 3 {
+			return a, errors.New("expected bollinger <period> <deviations> [above|below]")
+		}
+		if a.Period, err = parsePeriod(args[0]); err != nil {
+			return a, err
+		}
+		if a.Deviations, err = strconv.ParseFloat(args[1], 64); err != nil {
+			return a, fmt.Errorf("%q is not a number", args[1])
+		}
+		if len(args) == 3 {
+			a.Direction = strings.ToLower(args[2])
+		}
+	default:
+		return a, fmt.Errorf("unknown indicator %q, expected sma, ema, rsi or bollinger", a.Kind)
+	}
+	return a, Validate(a)
+}
+
+func parsePeriod(s string) (int, error) {
+	period, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a whole number of days", s)
+	}
+	return period, nil
+}
+
+func Validate(a schemas.IndicatorAlert) error {
+	checkPeriod := func(period int) error {
+		if period < 2 || period > MaxPeriod {
+			return fmt.Errorf("period %d is not between 2 and %d days", period, MaxPeriod)
+		}
+		return nil
+	}
+	checkDirection := func(optional bool) error {
+		if a.Direction == schemas.TriggerAbove || a.Direction == schemas.TriggerBelow || (optional && a.Direction == "") {
+			return nil
+		}
+		return fmt.Errorf("direction %q is not above or below", a.Direction)
+	}
+
+	switch a.Kind {
+	case schemas.IndicatorSMA, schemas.IndicatorEMA:
+		if err := checkPeriod(a.Fast); err != nil {
+			return err
+		}
+		if err := checkPeriod(a.Slow); err != nil {
+			return err
+		}
+		if a.Fast >= a.Slow {
+			return fmt.Errorf("the fast period %d must be shorter than the slow period %d", a.Fast, a.Slow)
+		}
+		return checkDirection(false)
+	case schemas.IndicatorRSI:
+		if err := checkPeriod(a.Period); err != nil {
+			return err
+		}
+		if a.Level <= 0 || a.Level >= 100 {
+			return fmt.Errorf("RSI level %v is not between 0 and 100", a.Level)
+		}
+		return checkDirection(false)
+	case schemas.IndicatorBollinger:
+		if err := checkPeriod(a.Period); err != nil {
+			return err
+		}
+		if a.Deviations <= 0 {
+			return fmt.Errorf("%v is not a positive number of standard deviations", a.Deviations)
+		}
+		return checkDirection(true)
+	}
+	return fmt.Errorf("unknown indicator %q", a.Kind)
+}
+
+// Evaluate reports whether a signals on the last of rates, that is whether the
+// crossing it watches for happened between the last two data points, and
+// describes the signal.
+func Evaluate(a schemas.IndicatorAlert, rates []schemas.HistoricalRate) (string, bool) {
+	n := len(rates)
+	if n < 2 {
+		return "", false
+	}
+
+	switch a.Kind {
+	case schemas.IndicatorSMA, schemas.IndicatorEMA:
+		average := SMA
+		if a.Kind == schemas.IndicatorEMA {
+			average = EMA
+		}
+		fast, slow := average(rates, a.Fast), average(rates, a.Slow)
+		if !crossed(fast[n-2]-slow[n-2], fast[n-1]-slow[n-1], a.Direction) {
+			return "", false
+		}
+		name := strings.ToUpper(a.Kind)
+		return fmt.Sprintf("%d-day %s crossed %s %d-day %s (%.4f vs %.4f)", a.Fast, name, a.Direction, a.Slow, name, fast[n-1], slow[n-1]), true
+	case schemas.IndicatorRSI:
+		rsi := RSI(rates, a.Period)
+		if !crossed(rsi[n-2]-a.Level, rsi[n-1]-a.Level, a.Direction) {
+			return "", false
+		}
+		return fmt.Sprintf("%d-day RSI crossed %s %s (%.1f)", a.Period, a.Direction, strconv.FormatFloat(a.Level, 'f', -1, 64), rsi[n-1]), true
+	case schemas.IndicatorBollinger:
+		bands := Bollinger(rates, a.Period, a.Deviations)
+		prev, last := bands[n-2], bands[n-1]
+		sigma := strconv.FormatFloat(a.Deviations, 'f', -1, 64)
+		if a.Direction != schemas.TriggerBelow && crossed(rates[n-2].Rate-prev.Upper, rates[n-1].Rate-last.Upper, schemas.TriggerAbove) {
+			return fmt.Sprintf("Rate rose above the upper %s-sigma %d-day Bollinger band (%.4f)", sigma, a.Period, last.Upper), true
+		}
+		if a.Direction != schemas.TriggerAbove && crossed(rates[n-2].Rate-prev.Lower, rates[n-1].Rate-last.Lower, schemas.TriggerBelow) {
+			return fmt.Sprintf("Rate fell below the lower %s-sigma %d-day Bollinger band (%.4f)", sigma, a.Period, last.Lower), true
+		}
+	}
+	return "", false
+}
+
+// crossed reports whether a difference that was on or behind zero at prev has
+// moved past it in direction at last.
+func crossed(prev, last float64, direction string) bool {
+	if math.IsNaN(prev) || math.IsNaN(last) {
+		return false
+	}
+	switch direction {
+	case schemas.TriggerAbove:
+		return prev <= 0 && last > 0
+	case schemas.TriggerBelow:
+		return prev >= 0 && last < 0
+	}
+	return false
+}
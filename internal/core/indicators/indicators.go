@@ -0,0 +1,131 @@
+// Package indicators computes technical indicators over daily rate history
+// and evaluates indicator alerts against them.
+//
+// Every series is aligned with its input: result[i] is the indicator value on
+// rates[i], or NaN while there is not enough history yet. Rates must be in
+// chronological order.
+package indicators
+
+import (
+	"math"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+)
+
+type Band struct {
+	Lower  float64
+	Middle float64
+	Upper  float64
+}
+
+func nanSeries(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
+
+// SMA is the simple moving average over period rates.
+func SMA(rates []schemas.HistoricalRate, period int) []float64 {
+	out := nanSeries(len(rates))
+	if period <= 0 {
+		return out
+	}
+	for i := period - 1; i < len(rates); i++ {
+		out[i] = mean(rates[i-period+1 : i+1])
+	}
+	return out
+}
+
+// mean sums each window afresh rather than keeping a running sum, so that
+// rounding errors do not build up and make equal averages compare unequal.
+func mean(rates []schemas.HistoricalRate) float64 {
+	var sum float64
+	for _, r := range rates {
+		sum += r.Rate
+	}
+	return sum / float64(len(rates))
+}
+
+// EMA is the exponential moving average over period rates, seeded with the
+// SMA of the first period rates.
+func EMA(rates []schemas.HistoricalRate, period int) []float64 {
+	out := nanSeries(len(rates))
+	if period <= 0 || len(rates) < period {
+		return out
+	}
+	out[period-1] = mean(rates[:period])
+
+	alpha := 2 / float64(period+1)
+	for i := period; i < len(rates); i++ {
+		out[i] = alpha*rates[i].Rate + (1-alpha)*out[i-1]
+	}
+	return out
+}
+
+// RSI is the relative strength index over period changes, using Wilder's
+// smoothing. It ranges from 0 to 100.
+func RSI(rates []schemas.HistoricalRate, period int) []float64 {
+	out := nanSeries(len(rates))
+	if period <= 0 || len(rates) <= period {
+		return out
+	}
+
+	var gain, loss float64
+	for i := 1; i <= period; i++ {
+		g, l := change(rates, i)
+		gain += g
+		loss += l
+	}
+	gain /= float64(period)
+	loss /= float64(period)
+	out[period] = relativeStrength(gain, loss)
+
+	for i := period + 1; i < len(rates); i++ {
+		g, l := change(rates, i)
+		gain = (gain*float64(period-1) + g) / float64(period)
+		loss = (loss*float64(period-1) + l) / float64(period)
+		out[i] = relativeStrength(gain, loss)
+	}
+	return out
+}
+
+func change(rates []schemas.HistoricalRate, i int) (gain, loss float64) {
+	diff := rates[i].Rate - rates[i-1].Rate
+	if diff > 0 {
+		return diff, 0
+	}
+	return 0, -diff
+}
+
+func relativeStrength(gain, loss float64) float64 {
+	if loss == 0 {
+		if gain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
+
+// Bollinger returns bands deviations population standard deviations either
+// side of the period SMA.
+func Bollinger(rates []schemas.HistoricalRate, period int, deviations float64) []Band {
+	middle := SMA(rates, period)
+	out := make([]Band, len(rates))
+	for i := range rates {
+		if math.IsNaN(middle[i]) {
+			out[i] = Band{Lower: math.NaN(), Middle: math.NaN(), Upper: math.NaN()}
+			continue
+		}
+		var variance float64
+		for _, r := range rates[i-period+1 : i+1] {
+			d := r.Rate - middle[i]
+			variance += d * d
+		}
+		width := deviations * math.Sqrt(variance/float64(period))
+		out[i] = Band{Lower: middle[i] - width, Middle: middle[i], Upper: middle[i] + width}
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func series(values ...float64) []schemas.HistoricalRate {
+	start := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	rates := make([]schemas.HistoricalRate, len(values))
+	for i, value := range values {
+		rates[i] = schemas.HistoricalRate{Date: start.AddDate(0, 0, i), Rate: value}
+	}
+	return rates
+}
+
+func assertSeries(t *testing.T, expected, actual []float64) {
+	t.Helper()
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		if math.IsNaN(expected[i]) {
+			assert.True(t, math.IsNaN(actual[i]), "index %d: got %v", i, actual[i])
+		} else {
+			assert.InDelta(t, expected[i], actual[i], 1e-9, "index %d", i)
+		}
+	}
+}
+
+func TestIndicators(t *testing.T) {
+	nan := math.NaN()
+	rates := series(1, 2, 3, 4, 5)
+
+	assertSeries(t, []float64{nan, nan, 2, 3, 4}, SMA(rates, 3))
+	assertSeries(t, []float64{nan, nan, 2, 3, 4}, EMA(rates, 3))
+	assertSeries(t, []float64{nan, nan, 2, 3.5, 4.25}, EMA(series(1, 2, 3, 5, 5), 3))
+	assertSeries(t, []float64{nan, nan, 100, 50}, RSI(series(1, 2, 3, 2), 2))
+	assertSeries(t, []float64{nan, 50, 50}, RSI(series(1, 1, 1), 1))
+	assertSeries(t, []float64{nan, nan}, SMA(series(1, 2), 3))
+
+	bands := Bollinger(series(1, 3, 3), 2, 2)
+	assert.True(t, math.IsNaN(bands[0].Middle))
+	assert.Equal(t, Band{Lower: 0, Middle: 2, Upper: 4}, bands[1])
+	assert.Equal(t, Band{Lower: 3, Middle: 3, Upper: 3}, bands[2])
+}
+
+func TestParseAlert(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected schemas.IndicatorAlert
+		err      string
+	}{
+		{args: []string{"SMA", "20", "50", "above"}, expected: schemas.IndicatorAlert{Kind: "sma", Fast: 20, Slow: 50, Direction: "above"}},
+		{args: []string{"ema", "12", "26", "below"}, expected: schemas.IndicatorAlert{Kind: "ema", Fast: 12, Slow: 26, Direction: "below"}},
+		{args: []string{"rsi", "14", "above", "70"}, expected: schemas.IndicatorAlert{Kind: "rsi", Period: 14, Level: 70, Direction: "above"}},
+		{args: []string{"bollinger", "20", "2"}, expected: schemas.IndicatorAlert{Kind: "bollinger", Period: 20, Deviations: 2}},
+		{args: []string{"bollinger", "20", "2.5", "below"}, expected: schemas.IndicatorAlert{Kind: "bollinger", Period: 20, Deviations: 2.5, Direction: "below"}},
+		{args: []string{"sma", "50", "20", "above"}, err: "must be shorter"},
+		{args: []string{"sma", "20", "500", "above"}, err: "not between 2 and 200 days"},
+		{args: []string{"sma", "20", "50", "sideways"}, err: `direction "sideways"`},
+		{args: []string{"rsi", "14", "above", "120"}, err: "not between 0 and 100"},
+		{args: []string{"rsi", "14", "above"}, err: "expected rsi"},
+		{args: []string{"bollinger", "20", "0"}, err: "not a positive number of standard deviations"},
+		{args: []string{"sma", "twenty", "50", "above"}, err: `"twenty" is not a whole number`},
+		{args: []string{"macd", "12", "26"}, err: "unknown indicator"},
+	}
+	for _, tt := range tests {
+		alert, err := ParseAlert(tt.args)
+		if tt.err != "" {
+			assert.ErrorContains(t, err, tt.err, "%v", tt.args)
+			continue
+		}
+		require.NoError(t, err, "%v", tt.args)
+		assert.Equal(t, tt.expected, alert)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	crossover := schemas.IndicatorAlert{Kind: schemas.IndicatorSMA, Fast: 2, Slow: 4, Direction: schemas.TriggerAbove}
+	signal, ok := Evaluate(crossover, series(1.3, 1.3, 1.3, 1.3, 1.4))
+	assert.True(t, ok)
+	assert.Equal(t, "2-day SMA crossed above 4-day SMA (1.3500 vs 1.3250)", signal)
+
+	_, ok = Evaluate(crossover, series(1.3, 1.3, 1.3, 1.4, 1.5))
+	assert.False(t, ok, "the fast average was already above the slow one")
+	crossover.Direction = schemas.TriggerBelow
+	_, ok = Evaluate(crossover, series(1.3, 1.3, 1.3, 1.3, 1.4))
+	assert.False(t, ok)
+	_, ok = Evaluate(crossover, series(1.3, 1.4))
+	assert.False(t, ok, "not enough history for the slow average")
+
+	rsi := schemas.IndicatorAlert{Kind: schemas.IndicatorRSI, Period: 2, Level: 30, Direction: schemas.TriggerBelow}
+	_, ok = Evaluate(rsi, series(1, 2, 3, 2))
+	assert.False(t, ok, "RSI fell to 50")
+	signal, ok = Evaluate(rsi, series(1, 2, 3, 2, 1))
+	assert.True(t, ok)
+	assert.Equal(t, "2-day RSI crossed below 30 (25.0)", signal)
+
+	band := schemas.IndicatorAlert{Kind: schemas.IndicatorBollinger, Period: 3, Deviations: 1}
+	signal, ok = Evaluate(band, series(1.3, 1.31, 1.29, 1.3, 1.36))
+	assert.True(t, ok)
+	assert.Contains(t, signal, "Rate rose above the upper 1-sigma 3-day Bollinger band")
+	signal, ok = Evaluate(band, series(1.3, 1.31, 1.29, 1.3, 1.24))
+	assert.True(t, ok)
+	assert.Contains(t, signal, "Rate fell below the lower 1-sigma 3-day Bollinger band")
+	band.Direction = schemas.TriggerAbove
+	_, ok = Evaluate(band, series(1.3, 1.31, 1.29, 1.3, 1.24))
+	assert.False(t, ok)
+}
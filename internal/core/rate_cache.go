@@ -0,0 +1,209 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	rateCacheMinTTL        = 1 * time.Minute
+	rateCacheHistoricalTTL = 24 * time.Hour
+	rateCachePruneSize     = 512
+)
+
+type rateCacheEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+type CachedProvider struct {
+	schemas.RateProvider
+	MaxTTL time.Duration
+
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]rateCacheEntry
+	group   singleflight.Group
+}
+
+func NewCachedProvider(provider schemas.RateProvider, maxTTL time.Duration) *CachedProvider {
+	return &CachedProvider{
+		RateProvider: provider,
+		MaxTTL:       maxTTL,
+		now:          time.Now,
+		entries:      make(map[string]rateCacheEntry),
+	}
+}
+
+func (p *CachedProvider) latestTTL() time.Duration {
+	ttl := p.MaxTTL
+	now := p.now()
+	if next, ok := schemas.NextPublicationOf(p.RateProvider, now); ok {
+		if untilNext := next.Sub(now); untilNext < ttl {
+			ttl = untilNext
+		}
+	}
+	if ttl < rateCacheMinTTL {
+		ttl = rateCacheMinTTL
+	}
+	return ttl
+}
+
+func (p *CachedProvider) lookup(key string) (interface{}, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !p.now().Before(entry.expiresAt) {
+		delete(p.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (p *CachedProvider) store(key string, value interface{}, ttl time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if len(p.entries) >= rateCachePruneSize {
+		for k, entry := range p.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(p.entries, k)
+			}
+		}
+	}
+	p.entries[key] = rateCacheEntry{value: value, expiresAt: now.Add(ttl)}
+}
+
+func (p *CachedProvider) fetch(key string, ttl func() time.Duration, load func() (interface{}, error)) (interface{}, error) {
+	if value, ok := p.lookup(key); ok {
+		return value, nil
+	}
+
+	value, err, shared := p.group.Do(key, func() (interface{}, error) {
+		if value, ok := p.lookup(key); ok {
+			return value, nil
+		}
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+		p.store(key, value, ttl())
+		return value, nil
+	})
+	if shared {
+		log.Debugf("Coalesced rate request %s", key)
+	}
+	return value, err
+}
+
+// fetchBatch serves each currency from its own cache entry and asks upstream
+// only for the ones that are missing, in a single coalesced call.
+func fetchBatch[T any](p *CachedProvider, prefix string, currencies []string, ttl func() time.Duration,
+	load func(missing []string) (map[string]T, error)) (map[string]T, error) {
+	found := make(map[string]T, len(currencies))
+	missing := make([]string, 0, len(currencies))
+	for _, currency := range currencies {
+		if value, ok := p.lookup(prefix + currency); ok {
+			found[currency] = value.(T)
+		} else {
+			missing = append(missing, currency)
+		}
+	}
+	if len(missing) == 0 {
+		return found, nil
+	}
+
+	key := prefix + strings.Join(missing, ",")
+	value, err, shared := p.group.Do(key, func() (interface{}, error) {
+		batch, err := load(missing)
+		if err != nil {
+			return nil, err
+		}
+		entryTTL := ttl()
+		for currency, value := range batch {
+			p.store(prefix+currency, value, entryTTL)
+		}
+		return batch, nil
+	})
+	if shared {
+		log.Debugf("Coalesced rate request %s", key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for currency, value := range value.(map[string]T) {
+		found[currency] = value
+	}
+	return found, nil
+}
+
+func (p *CachedProvider) LatestRates(base string, currencies []string) (map[string]*schemas.ExchangeRate, error) {
+	prefix := fmt.Sprintf("%s|latest|%s|", p.Name(), base)
+	quotes, err := fetchBatch(p, prefix, schemas.QuoteCurrencies(base, currencies), p.latestTTL,
+		func(missing []string) (map[string]*schemas.ExchangeRate, error) {
+			return p.RateProvider.LatestRates(base, missing)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	copies := make(map[string]*schemas.ExchangeRate, len(quotes))
+	for currency, quote := range quotes {
+		quoteCopy := *quote
+		copies[currency] = &quoteCopy
+	}
+	return copies, nil
+}
+
+func (p *CachedProvider) HistoricalRates(base string, currencies []string, start, end time.Time) (map[string][]schemas.HistoricalRate, error) {
+	startDate, endDate := start.Format("2006-01-02"), end.Format("2006-01-02")
+	prefix := fmt.Sprintf("%s|historical|%s|%s..%s|", p.Name(), base, startDate, endDate)
+
+	ttl := p.latestTTL
+	if endDate < p.now().Format("2006-01-02") {
+		ttl = func() time.Duration { return rateCacheHistoricalTTL }
+	}
+
+	histories, err := fetchBatch(p, prefix, schemas.QuoteCurrencies(base, currencies), ttl,
+		func(missing []string) (map[string][]schemas.HistoricalRate, error) {
+			return p.RateProvider.HistoricalRates(base, missing, start, end)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	copies := make(map[string][]schemas.HistoricalRate, len(histories))
+	for currency, rates := range histories {
+		copies[currency] = append([]schemas.HistoricalRate(nil), rates...)
+	}
+	return copies, nil
+}
+
+func (p *CachedProvider) SupportedCurrencies() ([]schemas.CurrencyInfo, error) {
+	key := fmt.Sprintf("%s|currencies", p.Name())
+	value, err := p.fetch(key, func() time.Duration { return rateCacheHistoricalTTL }, func() (interface{}, error) {
+		return p.RateProvider.SupportedCurrencies()
+	})
+	if err != nil {
+		return nil, err
+	}
+	currencies := value.([]schemas.CurrencyInfo)
+	return append([]schemas.CurrencyInfo(nil), currencies...), nil
+}
+
+func (p *CachedProvider) NextPublication(after time.Time) time.Time {
+	next, _ := schemas.NextPublicationOf(p.RateProvider, after)
+	return next
+}
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingProvider struct {
+	stubRateProvider
+	calls     atomic.Int32
+	release   chan struct{}
+	next      time.Time
+	requested []string
+}
+
+func (p *countingProvider) LatestRates(base string, currencies []string) (map[string]*schemas.ExchangeRate, error) {
+	p.calls.Add(1)
+	p.requested = currencies
+	if p.release != nil {
+		<-p.release
+	}
+	return p.stubRateProvider.LatestRates(base, currencies)
+}
+
+func (p *countingProvider) HistoricalRates(base string, currencies []string, start, end time.Time) (map[string][]schemas.HistoricalRate, error) {
+	p.calls.Add(1)
+	p.requested = currencies
+	return p.stubRateProvider.HistoricalRates(base, currencies, start, end)
+}
+
+func (p *countingProvider) NextPublication(after time.Time) time.Time {
+	return p.next
+}
+
+func newTestCache(provider schemas.RateProvider, now *time.Time) *CachedProvider {
+	cache := NewCachedProvider(provider, 3*time.Hour)
+	cache.now = func() time.Time { return *now }
+	return cache
+}
+
+func TestCachedProvider_CoalescesConcurrentRequests(t *testing.T) {
+	now := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+	upstream := &countingProvider{
+		stubRateProvider: stubRateProvider{rates: map[string]float64{"USD": 1.35}},
+		release:          make(chan struct{}),
+		next:             now.Add(time.Hour),
+	}
+	cache := newTestCache(upstream, &now)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			quote, err := schemas.FetchLatestRate(cache, "SGD", "USD")
+			assert.NoError(t, err)
+			assert.Equal(t, 1.35, quote.Rate)
+		}()
+	}
+
+	require.Eventually(t, func() bool { return upstream.calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(upstream.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), upstream.calls.Load())
+}
+
+func TestCachedProvider_ExpiresAtNextPublication(t *testing.T) {
+	now := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+	upstream := &countingProvider{
+		stubRateProvider: stubRateProvider{rates: map[string]float64{"USD": 1.35}},
+		next:             now.Add(2 * time.Hour),
+	}
+	cache := newTestCache(upstream, &now)
+
+	_, err := schemas.FetchLatestRate(cache, "SGD", "USD")
+	require.NoError(t, err)
+	_, err = schemas.FetchLatestRate(cache, "SGD", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), upstream.calls.Load())
+
+	now = now.Add(2*time.Hour + time.Second)
+	_, err = schemas.FetchLatestRate(cache, "SGD", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), upstream.calls.Load())
+}
+
+func TestCachedProvider_MaxTTLCapsLongGaps(t *testing.T) {
+	now := time.Date(2026, 2, 21, 9, 0, 0, 0, time.UTC)
+	upstream := &countingProvider{
+		stubRateProvider: stubRateProvider{rates: map[string]float64{"USD": 1.35}},
+		next:             now.Add(48 * time.Hour),
+	}
+	cache := newTestCache(upstream, &now)
+
+	_, err := schemas.FetchLatestRate(cache, "SGD", "USD")
+	require.NoError(t, err)
+
+	now = now.Add(3*time.Hour + time.Second)
+	_, err = schemas.FetchLatestRate(cache, "SGD", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), upstream.calls.Load())
+}
+
+func TestCachedProvider_KeysByPairAndRange(t *testing.T) {
+	now := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+	upstream := &countingProvider{
+		stubRateProvider: stubRateProvider{rates: map[string]float64{"USD": 1.35, "EUR": 1.45}},
+		next:             now.Add(time.Hour),
+	}
+	cache := newTestCache(upstream, &now)
+
+	schemas.FetchLatestRate(cache, "SGD", "USD")
+	schemas.FetchLatestRate(cache, "SGD", "EUR")
+	schemas.FetchLatestRate(cache, "MYR", "USD")
+	assert.Equal(t, int32(3), upstream.calls.Load())
+
+	start := now.AddDate(0, -1, 0)
+	schemas.FetchHistoricalRates(cache, "SGD", "USD", start, now)
+	schemas.FetchHistoricalRates(cache, "SGD", "USD", start.Add(time.Minute), now.Add(time.Minute))
+	assert.Equal(t, int32(4), upstream.calls.Load())
+
+	schemas.FetchHistoricalRates(cache, "SGD", "USD", start.AddDate(0, 0, -1), now)
+	assert.Equal(t, int32(5), upstream.calls.Load())
+}
+
+func TestCachedProvider_DoesNotCacheErrors(t *testing.T) {
+	now := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+	upstream := &countingProvider{next: now.Add(time.Hour)}
+	cache := newTestCache(upstream, &now)
+
+	_, err := schemas.FetchLatestRate(cache, "SGD", "USD")
+	assert.Error(t, err)
+	_, err = schemas.FetchLatestRate(cache, "SGD", "USD")
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), upstream.calls.Load())
+}
+
+func TestCachedProvider_ReturnsCopies(t *testing.T) {
+	now := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+	upstream := &countingProvider{
+		stubRateProvider: stubRateProvider{rates: map[string]float64{"USD": 1.35}},
+		next:             now.Add(time.Hour),
+	}
+	cache := newTestCache(upstream, &now)
+
+	quote, err := schemas.FetchLatestRate(cache, "SGD", "USD")
+	require.NoError(t, err)
+	quote.CrossCheck = &schemas.CrossCheck{Exceeded: true}
+
+	quote, err = schemas.FetchLatestRate(cache, "SGD", "USD")
+	require.NoError(t, err)
+	assert.Nil(t, quote.CrossCheck)
+}
+
+func TestCachedProvider_BatchFetchesOnlyMissingCurrencies(t *testing.T) {
+	now := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+	upstream := &countingProvider{
+		stubRateProvider: stubRateProvider{rates: map[string]float64{"USD": 1.35, "EUR": 1.45, "JPY": 0.0089}},
+		next:             now.Add(time.Hour),
+	}
+	cache := newTestCache(upstream, &now)
+
+	_, err := schemas.FetchLatestRate(cache, "SGD", "USD")
+	require.NoError(t, err)
+
+	quotes, err := cache.LatestRates("SGD", []string{"USD", "EUR", "JPY"})
+	require.NoError(t, err)
+	assert.Len(t, quotes, 3)
+	assert.Equal(t, int32(2), upstream.calls.Load())
+	assert.Equal(t, []string{"EUR", "JPY"}, upstream.requested)
+
+	quotes, err = cache.LatestRates("SGD", []string{"JPY", "EUR", "USD"})
+	require.NoError(t, err)
+	assert.Len(t, quotes, 3)
+	assert.Equal(t, int32(2), upstream.calls.Load())
+}
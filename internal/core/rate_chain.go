@@ -0,0 +1,213 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	CrossCheckModeFlag     = "flag"
+	CrossCheckModeSuppress = "suppress"
+)
+
+func ValidateCrossCheckMode(mode string) error {
+	switch mode {
+	case CrossCheckModeFlag, CrossCheckModeSuppress:
+		return nil
+	}
+	return fmt.Errorf("unknown cross-check mode %q, expected %s or %s", mode, CrossCheckModeFlag, CrossCheckModeSuppress)
+}
+
+type FallbackProvider struct {
+	Providers []schemas.RateProvider
+}
+
+func NewFallbackProvider(providers ...schemas.RateProvider) *FallbackProvider {
+	return &FallbackProvider{Providers: providers}
+}
+
+func (p *FallbackProvider) Name() string {
+	names := make([]string, len(p.Providers))
+	for i, provider := range p.Providers {
+		names[i] = provider.Name()
+	}
+	return strings.Join(names, " → ")
+}
+
+func missingCurrencies[T any](currencies []string, found map[string]T) []string {
+	missing := make([]string, 0, len(currencies))
+	for _, currency := range currencies {
+		if _, ok := found[currency]; !ok {
+			missing = append(missing, currency)
+		}
+	}
+	return missing
+}
+
+// Each provider is only asked for the currencies the providers before it could
+// not quote, so a partial answer from the primary still saves a round trip.
+func fallbackBatch[T any](providers []schemas.RateProvider, base string, currencies []string, kind string,
+	fetch func(provider schemas.RateProvider, currencies []string) (map[string]T, error)) (map[string]T, error) {
+	found := make(map[string]T)
+	remaining := schemas.QuoteCurrencies(base, currencies)
+
+	var errs []error
+	for _, provider := range providers {
+		if len(remaining) == 0 {
+			break
+		}
+		batch, err := fetch(provider, remaining)
+		if err != nil {
+			log.Warnf("%s failed to fetch %s for %v against %s, trying next provider: %v", provider.Name(), kind, remaining, base, err)
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+		for currency, value := range batch {
+			found[currency] = value
+		}
+		remaining = missingCurrencies(remaining, found)
+	}
+
+	if len(found) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return found, nil
+}
+
+func (p *FallbackProvider) LatestRates(base string, currencies []string) (map[string]*schemas.ExchangeRate, error) {
+	return fallbackBatch(p.Providers, base, currencies, "latest rates",
+		func(provider schemas.RateProvider, currencies []string) (map[string]*schemas.ExchangeRate, error) {
+			return provider.LatestRates(base, currencies)
+		})
+}
+
+func (p *FallbackProvider) HistoricalRates(base string, currencies []string, start, end time.Time) (map[string][]schemas.HistoricalRate, error) {
+	return fallbackBatch(p.Providers, base, currencies, "historical rates",
+		func(provider schemas.RateProvider, currencies []string) (map[string][]schemas.HistoricalRate, error) {
+			return provider.HistoricalRates(base, currencies, start, end)
+		})
+}
+
+// SupportedCurrencies is the union across providers, since a currency the
+// primary lacks is still served by whichever fallback quotes it.
+func (p *FallbackProvider) SupportedCurrencies() ([]schemas.CurrencyInfo, error) {
+	var errs []error
+	var currencies []schemas.CurrencyInfo
+	seen := make(map[string]bool)
+	for _, provider := range p.Providers {
+		provided, err := provider.SupportedCurrencies()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+		for _, c := range provided {
+			if !seen[c.Code] {
+				seen[c.Code] = true
+				currencies = append(currencies, c)
+			}
+		}
+	}
+	if len(currencies) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return currencies, nil
+}
+
+// NextPublication is the earliest schedule among the providers, since any of
+// them may be the one serving a given currency.
+func (p *FallbackProvider) NextPublication(after time.Time) time.Time {
+	var earliest time.Time
+	for _, provider := range p.Providers {
+		next, ok := schemas.NextPublicationOf(provider, after)
+		if ok && (earliest.IsZero() || next.Before(earliest)) {
+			earliest = next
+		}
+	}
+	return earliest
+}
+
+type CrossCheckedProvider struct {
+	schemas.RateProvider
+	Reference schemas.RateProvider
+	Tolerance float64
+}
+
+func NewCrossCheckedProvider(primary, reference schemas.RateProvider, tolerancePercent float64) *CrossCheckedProvider {
+	return &CrossCheckedProvider{
+		RateProvider: primary,
+		Reference:    reference,
+		Tolerance:    tolerancePercent / 100,
+	}
+}
+
+// crossCheckEach wraps every provider in the fallback chain on its own, so
+// that a quote is always compared with the reference rather than with
+// whichever provider served it. The reference itself is left unchecked.
+func crossCheckEach(providers []schemas.RateProvider, reference schemas.RateProvider, tolerancePercent float64) []schemas.RateProvider {
+	checked := make([]schemas.RateProvider, len(providers))
+	for i, provider := range providers {
+		checked[i] = provider
+		if provider.Name() != reference.Name() {
+			checked[i] = NewCrossCheckedProvider(provider, reference, tolerancePercent)
+		}
+	}
+	return checked
+}
+
+func (p *CrossCheckedProvider) LatestRates(base string, currencies []string) (map[string]*schemas.ExchangeRate, error) {
+	quotes, err := p.RateProvider.LatestRates(base, currencies)
+	if err != nil || len(quotes) == 0 {
+		return quotes, err
+	}
+
+	quoted := make([]string, 0, len(quotes))
+	for currency := range quotes {
+		quoted = append(quoted, currency)
+	}
+	references, err := p.Reference.LatestRates(base, quoted)
+	if err != nil {
+		log.Warnf("Unable to cross-check %v rates against %s: %v", quoted, p.Reference.Name(), err)
+		return quotes, nil
+	}
+
+	for currency, quote := range quotes {
+		reference, ok := references[currency]
+		if !ok {
+			log.Warnf("Unable to cross-check %s/%s rate, %s has no quote", currency, base, p.Reference.Name())
+			continue
+		}
+
+		deviation := math.Abs(quote.Rate-reference.Rate) / reference.Rate
+		quote.CrossCheck = &schemas.CrossCheck{
+			Source:    reference.Source,
+			Rate:      reference.Rate,
+			Date:      reference.Date,
+			Deviation: deviation,
+			Exceeded:  deviation > p.Tolerance,
+		}
+		if quote.CrossCheck.Exceeded {
+			log.Warnf("%s rate for %s/%s (%.6f) deviates %.2f%% from %s (%.6f)",
+				quote.Source, currency, base, quote.Rate, deviation*100, reference.Source, reference.Rate)
+		}
+	}
+	return quotes, nil
+}
+
+func (p *CrossCheckedProvider) NextPublication(after time.Time) time.Time {
+	next, _ := schemas.NextPublicationOf(p.RateProvider, after)
+	return next
+}
+
+func FormatCrossCheckWarning(quote *schemas.ExchangeRate) string {
+	if !quote.IsDisputed() {
+		return ""
+	}
+	return fmt.Sprintf("⚠️ %s reports %.4f (%s), %.2f%% away from this rate\n",
+		quote.CrossCheck.Source, quote.CrossCheck.Rate, quote.CrossCheck.Date, quote.CrossCheck.Deviation*100)
+}
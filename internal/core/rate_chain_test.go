@@ -0,0 +1,148 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallbackProvider_FallsThroughOnError(t *testing.T) {
+	failing := &stubRateProvider{name: "Primary", err: errors.New("status 503")}
+	backup := &stubRateProvider{name: "Backup", rates: map[string]float64{"USD": 1.35}}
+
+	provider := NewFallbackProvider(failing, backup)
+	assert.Equal(t, "Primary → Backup", provider.Name())
+
+	quote, err := schemas.FetchLatestRate(provider, "SGD", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, "Backup", quote.Source)
+	assert.Equal(t, 1.35, quote.Rate)
+}
+
+func TestFallbackProvider_AllFail(t *testing.T) {
+	provider := NewFallbackProvider(
+		&stubRateProvider{name: "Primary", err: errors.New("status 503")},
+		&stubRateProvider{name: "Backup", err: errors.New("timeout")},
+	)
+
+	_, err := schemas.FetchLatestRate(provider, "SGD", "USD")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Primary: status 503")
+	assert.Contains(t, err.Error(), "Backup: timeout")
+
+	_, err = schemas.FetchHistoricalRates(provider, "SGD", "USD", time.Now().AddDate(0, -1, 0), time.Now())
+	assert.Error(t, err)
+}
+
+func TestFallbackProvider_UsesFirstHealthyProvider(t *testing.T) {
+	primary := &stubRateProvider{name: "Primary", rates: map[string]float64{"USD": 1.35}}
+	backup := &stubRateProvider{name: "Backup", rates: map[string]float64{"USD": 1.50}}
+
+	quote, err := schemas.FetchLatestRate(NewFallbackProvider(primary, backup), "SGD", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, "Primary", quote.Source)
+}
+
+func TestCrossCheckedProvider_WithinTolerance(t *testing.T) {
+	primary := &stubRateProvider{name: "Primary", rates: map[string]float64{"USD": 1.3500}}
+	reference := &stubRateProvider{name: "Reference", rates: map[string]float64{"USD": 1.3510}}
+
+	quote, err := schemas.FetchLatestRate(NewCrossCheckedProvider(primary, reference, 0.5), "SGD", "USD")
+	require.NoError(t, err)
+	require.NotNil(t, quote.CrossCheck)
+	assert.False(t, quote.IsDisputed())
+	assert.Empty(t, FormatCrossCheckWarning(quote))
+}
+
+func TestCrossCheckedProvider_ExceedsTolerance(t *testing.T) {
+	primary := &stubRateProvider{name: "Primary", rates: map[string]float64{"USD": 1.4000}}
+	reference := &stubRateProvider{name: "Reference", rates: map[string]float64{"USD": 1.3500}}
+
+	quote, err := schemas.FetchLatestRate(NewCrossCheckedProvider(primary, reference, 0.5), "SGD", "USD")
+	require.NoError(t, err)
+	assert.True(t, quote.IsDisputed())
+	assert.InDelta(t, 0.037, quote.CrossCheck.Deviation, 0.001)
+	assert.Contains(t, FormatCrossCheckWarning(quote), "Reference reports 1.3500")
+}
+
+func TestCrossCheckedProvider_ReferenceUnavailable(t *testing.T) {
+	primary := &stubRateProvider{name: "Primary", rates: map[string]float64{"USD": 1.4000}}
+	reference := &stubRateProvider{name: "Reference", err: errors.New("timeout")}
+
+	quote, err := schemas.FetchLatestRate(NewCrossCheckedProvider(primary, reference, 0.5), "SGD", "USD")
+	require.NoError(t, err)
+	assert.Nil(t, quote.CrossCheck)
+	assert.False(t, quote.IsDisputed())
+}
+
+func TestCrossCheckEach_ComparesTheServingProviderWithTheReference(t *testing.T) {
+	primary := &stubRateProvider{name: "Primary", err: errors.New("outage")}
+	secondary := &stubRateProvider{name: "Secondary", rates: map[string]float64{"USD": 1.40}}
+	reference := &stubRateProvider{name: "Secondary", rates: map[string]float64{"USD": 1.40}}
+
+	chain := NewFallbackProvider(crossCheckEach([]schemas.RateProvider{primary, secondary}, reference, 0.5)...)
+	quote, err := schemas.FetchLatestRate(chain, "SGD", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, "Secondary", quote.Source)
+	assert.Nil(t, quote.CrossCheck, "the reference is not checked against itself")
+
+	third := &stubRateProvider{name: "Third", rates: map[string]float64{"USD": 1.35}}
+	chain = NewFallbackProvider(crossCheckEach([]schemas.RateProvider{primary, third}, reference, 0.5)...)
+	quote, err = schemas.FetchLatestRate(chain, "SGD", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, "Third", quote.Source)
+	assert.True(t, quote.IsDisputed())
+}
+
+func TestValidateCrossCheckMode(t *testing.T) {
+	assert.NoError(t, ValidateCrossCheckMode(CrossCheckModeFlag))
+	assert.NoError(t, ValidateCrossCheckMode(CrossCheckModeSuppress))
+	assert.ErrorContains(t, ValidateCrossCheckMode("supress"), `unknown cross-check mode "supress"`)
+}
+
+func TestBuildRateProvider(t *testing.T) {
+	provider, err := BuildRateProvider(RateProviderOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Frankfurter", provider.Name())
+
+	provider, err = BuildRateProvider(RateProviderOptions{Providers: []string{"frankfurter", " mas"}})
+	require.NoError(t, err)
+	assert.IsType(t, &FallbackProvider{}, provider)
+	assert.Equal(t, "Frankfurter → MAS", provider.Name())
+
+	provider, err = BuildRateProvider(RateProviderOptions{Providers: []string{"frankfurter"}, CrossCheck: "mas", TolerancePercent: 0.5})
+	require.NoError(t, err)
+	assert.IsType(t, &CrossCheckedProvider{}, provider)
+
+	provider, err = BuildRateProvider(RateProviderOptions{Providers: []string{"frankfurter", "mas"}, CrossCheck: "mas", TolerancePercent: 0.5})
+	require.NoError(t, err)
+	require.IsType(t, &FallbackProvider{}, provider)
+	assert.IsType(t, &CrossCheckedProvider{}, provider.(*FallbackProvider).Providers[0])
+	assert.IsType(t, &schemas.MASProvider{}, provider.(*FallbackProvider).Providers[1])
+
+	provider, err = BuildRateProvider(RateProviderOptions{Providers: []string{"mas"}, Pivots: []string{"SGD"}})
+	require.NoError(t, err)
+	assert.IsType(t, &TriangulatingProvider{}, provider)
+
+	provider, err = BuildRateProvider(RateProviderOptions{Providers: []string{"frankfurter"}, CacheTTL: time.Hour})
+	require.NoError(t, err)
+	assert.IsType(t, &CachedProvider{}, provider)
+	assert.Equal(t, "Frankfurter", provider.Name())
+
+	_, err = BuildRateProvider(RateProviderOptions{Providers: []string{"frankfurter", "unknown"}})
+	assert.Error(t, err)
+}
+
+func TestFallbackProvider_FillsMissingCurrenciesFromNextProvider(t *testing.T) {
+	primary := &stubRateProvider{name: "Primary", rates: map[string]float64{"USD": 1.35}}
+	backup := &stubRateProvider{name: "Backup", rates: map[string]float64{"USD": 1.50, "EUR": 1.45}}
+
+	quotes, err := NewFallbackProvider(primary, backup).LatestRates("SGD", []string{"USD", "EUR"})
+	require.NoError(t, err)
+	assert.Equal(t, "Primary", quotes["USD"].Source)
+	assert.Equal(t, "Backup", quotes["EUR"].Source)
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	historyCoverageSlack = 7 * 24 * time.Hour
+	backfillChunkYears   = 1
+)
+
+type HistoryBackedProvider struct {
+	schemas.RateProvider
+	Store schemas.RateHistoryStore
+}
+
+func NewHistoryBackedProvider(provider schemas.RateProvider, store schemas.RateHistoryStore) *HistoryBackedProvider {
+	return &HistoryBackedProvider{RateProvider: provider, Store: store}
+}
+
+func coversRange(rates []schemas.HistoricalRate, start, end time.Time) bool {
+	if len(rates) == 0 {
+		return false
+	}
+	// weekends and holidays leave gaps at either end of a fully stored range
+	return !rates[0].Date.After(start.Add(historyCoverageSlack)) &&
+		!rates[len(rates)-1].Date.Before(end.Add(-historyCoverageSlack))
+}
+
+func (p *HistoryBackedProvider) HistoricalRates(base string, currencies []string, start, end time.Time) (map[string][]schemas.HistoricalRate, error) {
+	histories := make(map[string][]schemas.HistoricalRate)
+	stored := make(map[string][]schemas.HistoricalRate)
+	missing := make([]string, 0, len(currencies))
+
+	for _, currency := range schemas.QuoteCurrencies(base, currencies) {
+		rates, err := p.Store.GetStoredRates(base, currency, start, end)
+		if err != nil {
+			log.Warnf("Error reading stored rates for %s/%s: %v", currency, base, err)
+		} else if coversRange(rates, start, end) {
+			histories[currency] = rates
+			continue
+		} else if len(rates) > 0 {
+			stored[currency] = rates
+		}
+		missing = append(missing, currency)
+	}
+	if len(missing) == 0 {
+		return histories, nil
+	}
+
+	upstream, err := p.RateProvider.HistoricalRates(base, missing, start, end)
+	if err != nil {
+		if len(histories) == 0 && len(stored) == 0 {
+			return nil, err
+		}
+		log.Warnf("Serving stored rates for %v against %s, upstream unavailable: %v", missing, base, err)
+		upstream = stored
+	}
+	for currency, rates := range upstream {
+		histories[currency] = rates
+	}
+	return histories, nil
+}
+
+func BackfillRateHistory(provider schemas.RateProvider, store schemas.RateHistoryStore, base string, currencies []string, from, to time.Time) (int, error) {
+	currencies = schemas.QuoteCurrencies(base, currencies)
+	inserted := 0
+	for chunkStart := from; !chunkStart.After(to); chunkStart = chunkStart.AddDate(backfillChunkYears, 0, 0) {
+		chunkEnd := chunkStart.AddDate(backfillChunkYears, 0, -1)
+		if chunkEnd.After(to) {
+			chunkEnd = to
+		}
+
+		histories, err := provider.HistoricalRates(base, currencies, chunkStart, chunkEnd)
+		if err != nil {
+			return inserted, fmt.Errorf("fetching %s rates %s..%s: %w", base,
+				chunkStart.Format("2006-01-02"), chunkEnd.Format("2006-01-02"), err)
+		}
+
+		for _, currency := range currencies {
+			rates := histories[currency]
+			if len(rates) == 0 {
+				continue
+			}
+			existing, err := store.GetStoredRates(base, currency, chunkStart, chunkEnd)
+			if err != nil {
+				return inserted, err
+			}
+
+			known := make(map[time.Time]bool, len(existing))
+			for _, r := range existing {
+				known[r.Date] = true
+			}
+
+			missing := make([]schemas.StoredRate, 0, len(rates))
+			for _, r := range rates {
+				if known[r.Date] {
+					continue
+				}
+				missing = append(missing, schemas.StoredRate{
+					Source:   provider.Name(),
+					Base:     base,
+					Currency: currency,
+					Date:     r.Date.Format("2006-01-02"),
+					Rate:     r.Rate,
+				})
+			}
+			if err := store.InsertStoredRates(missing); err != nil {
+				return inserted, err
+			}
+			inserted += len(missing)
+			log.Infof("Backfilled %d %s/%s rates for %s..%s", len(missing), currency, base,
+				chunkStart.Format("2006-01-02"), chunkEnd.Format("2006-01-02"))
+		}
+	}
+	return inserted, nil
+}
+
+func AppendLatestRates(provider schemas.RateProvider, store schemas.RateHistoryStore, base string, currencies []string) (int, error) {
+	quotes, err := provider.LatestRates(base, currencies)
+	if err != nil {
+		return 0, err
+	}
+
+	byDate := make(map[string][]*schemas.ExchangeRate)
+	for _, quote := range quotes {
+		if quote.Via != "" {
+			continue
+		}
+		byDate[quote.Date] = append(byDate[quote.Date], quote)
+	}
+
+	inserted := 0
+	for date, quotes := range byDate {
+		existing, err := store.GetStoredRatesOnDate(base, date)
+		if err != nil {
+			return inserted, err
+		}
+		known := make(map[string]bool, len(existing))
+		for _, r := range existing {
+			known[r.Currency] = true
+		}
+
+		missing := make([]schemas.StoredRate, 0, len(quotes))
+		for _, quote := range quotes {
+			if known[quote.Currency] {
+				continue
+			}
+			missing = append(missing, schemas.StoredRate{
+				Source:   quote.Source,
+				Base:     base,
+				Currency: quote.Currency,
+				Date:     date,
+				Rate:     quote.Rate,
+			})
+		}
+		if err := store.InsertStoredRates(missing); err != nil {
+			return inserted, err
+		}
+		inserted += len(missing)
+	}
+	return inserted, nil
+}
+
+func (p *HistoryBackedProvider) NextPublication(after time.Time) time.Time {
+	next, _ := schemas.NextPublicationOf(p.RateProvider, after)
+	return next
+}
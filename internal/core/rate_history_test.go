@@ -0,0 +1,90 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dailyRates(start time.Time, days int, rate float64) []schemas.HistoricalRate {
+	rates := make([]schemas.HistoricalRate, 0, days)
+	for i := 0; i < days; i++ {
+		rates = append(rates, schemas.HistoricalRate{Date: start.AddDate(0, 0, i), Rate: rate})
+	}
+	return rates
+}
+
+func TestHistoryBackedProvider_ServesStoredRangeWithoutUpstream(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := store.NewMemoryRateHistory()
+	_, err := BackfillRateHistory(&stubRateProvider{history: dailyRates(start, 30, 1.3)}, history, "SGD", []string{"USD"}, start, start.AddDate(0, 0, 29))
+	require.NoError(t, err)
+
+	provider := NewHistoryBackedProvider(&stubRateProvider{err: errors.New("offline")}, history)
+	rates, err := schemas.FetchHistoricalRates(provider, "SGD", "USD", start, start.AddDate(0, 0, 29))
+	require.NoError(t, err)
+	assert.Len(t, rates, 30)
+}
+
+func TestHistoryBackedProvider_FallsThroughOnPartialCoverage(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := store.NewMemoryRateHistory()
+	history.InsertStoredRates([]schemas.StoredRate{{Base: "SGD", Currency: "USD", Date: "2025-01-20", Rate: 1.3}})
+
+	upstream := &stubRateProvider{history: dailyRates(start, 30, 1.4)}
+	provider := NewHistoryBackedProvider(upstream, history)
+	rates, err := schemas.FetchHistoricalRates(provider, "SGD", "USD", start, start.AddDate(0, 0, 29))
+	require.NoError(t, err)
+	assert.Len(t, rates, 30)
+	assert.Equal(t, start, upstream.start)
+
+	upstream.err = errors.New("offline")
+	rates, err = schemas.FetchHistoricalRates(provider, "SGD", "USD", start, start.AddDate(0, 0, 29))
+	require.NoError(t, err)
+	assert.Len(t, rates, 1)
+}
+
+func TestBackfillRateHistory_SkipsStoredDates(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 9)
+	history := store.NewMemoryRateHistory()
+	upstream := &stubRateProvider{history: dailyRates(start, 10, 1.3)}
+
+	inserted, err := BackfillRateHistory(upstream, history, "SGD", []string{"SGD", "USD"}, start, end)
+	require.NoError(t, err)
+	assert.Equal(t, 10, inserted)
+
+	inserted, err = BackfillRateHistory(upstream, history, "SGD", []string{"USD"}, start, end)
+	require.NoError(t, err)
+	assert.Equal(t, 0, inserted)
+	stored, err := history.GetStoredRates("SGD", "USD", start, end)
+	require.NoError(t, err)
+	assert.Len(t, stored, 10)
+	onDate, err := history.GetStoredRatesOnDate("SGD", "2024-06-01")
+	require.NoError(t, err)
+	require.Len(t, onDate, 1)
+	assert.Equal(t, "Stub", onDate[0].Source)
+}
+
+func TestAppendLatestRates_InsertsOncePerDay(t *testing.T) {
+	history := store.NewMemoryRateHistory()
+	upstream := &stubRateProvider{rates: map[string]float64{"USD": 1.35, "EUR": 1.45}}
+
+	inserted, err := AppendLatestRates(upstream, history, "SGD", []string{"SGD", "USD", "EUR", "JPY"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, inserted)
+
+	inserted, err = AppendLatestRates(upstream, history, "SGD", []string{"USD", "EUR"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, inserted)
+	for _, currency := range []string{"USD", "EUR"} {
+		stored, err := history.GetStoredRates("SGD", currency, time.Time{}, time.Now().AddDate(1, 0, 0))
+		require.NoError(t, err)
+		assert.Len(t, stored, 1)
+	}
+}
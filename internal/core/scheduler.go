@@ -1,15 +1,26 @@
 package core
 
 import (
+	"context"
+	"errors"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/Jason-CKY/telegram-notifybot/internal/core/indicators"
 	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/store"
+	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	log "github.com/sirupsen/logrus"
 )
 
-func StartFXScheduler(bot *tgbotapi.BotAPI) {
+type MessageSender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+}
+
+func StartFXScheduler(bot MessageSender) {
 	localTimezone, err := time.LoadLocation("Asia/Singapore")
 	if err != nil {
 		panic(err)
@@ -19,123 +30,399 @@ func StartFXScheduler(bot *tgbotapi.BotAPI) {
 	defer ticker.Stop()
 
 	checkAndNotify(bot, localTimezone)
+	recordRateHistory()
 
 	for range ticker.C {
 		checkAndNotify(bot, localTimezone)
+		recordRateHistory()
 	}
 }
 
-func checkAndNotify(bot *tgbotapi.BotAPI, timezone *time.Location) {
-	subscriptions, err := schemas.GetAllActiveSubscriptions()
+func recordRateHistory() {
+	if RateHistory == nil {
+		return
+	}
+	inserted, err := AppendLatestRates(ActiveRateProvider, RateHistory, utils.DEFAULT_BASE_CURRENCY, utils.SupportedCurrencyCodes())
 	if err != nil {
-		log.Errorf("Error fetching subscriptions: %v", err)
+		log.Errorf("Error appending rate history: %v", err)
 		return
 	}
+	if inserted > 0 {
+		log.Infof("Stored %d new rates in rate history", inserted)
+	}
+}
 
-	if len(subscriptions) == 0 {
-		return
+type alertRun struct {
+	bot      MessageSender
+	timezone *time.Location
+	due      bool
+
+	quoted     map[string]bool
+	fresh      map[string]bool
+	rates      map[string]float64
+	quotes     map[string]*schemas.ExchangeRate
+	loaded     map[string]bool
+	histories  map[string][]schemas.HistoricalRate
+	oldestDate string
+
+	wg        sync.WaitGroup
+	evaluated int
+	sent      atomic.Int64
+
+	mu     sync.Mutex
+	failed map[string]bool
+}
+
+func checkAndNotify(bot MessageSender, timezone *time.Location) {
+	run := &alertRun{
+		bot:       bot,
+		timezone:  timezone,
+		due:       rateDataDates.Due(time.Now()),
+		quoted:    make(map[string]bool),
+		fresh:     make(map[string]bool),
+		rates:     make(map[string]float64),
+		quotes:    make(map[string]*schemas.ExchangeRate),
+		loaded:    make(map[string]bool),
+		histories: make(map[string][]schemas.HistoricalRate),
+		failed:    make(map[string]bool),
+	}
+
+	pages, err := store.ForEachActivePage(context.Background(), utils.SubscriptionPageSize, run.evaluatePage)
+	run.wg.Wait()
+	if err != nil {
+		log.Errorf("Error fetching subscriptions after %d pages: %v", pages, err)
+	}
+	if run.evaluated > 0 {
+		log.Infof("Evaluated %d active subscriptions across %d pages, sent %d notifications", run.evaluated, pages, run.sent.Load())
 	}
 
-	currencyRates := make(map[string]float64)
-	currencyHistories := make(map[string][]schemas.HistoricalRate)
+	for pair, quote := range run.quotes {
+		if run.failed[pair] {
+			log.Infof("Keeping %s data from %s unseen, some of its alerts are retried on the next run", pair, quote.Date)
+			continue
+		}
+		rateDataDates.MarkSeen(pair, quote.Date)
+	}
+	if run.oldestDate != "" {
+		rateDataDates.Schedule(ActiveRateProvider, run.oldestDate)
+	}
+}
 
+func (run *alertRun) evaluatePage(subscriptions []schemas.CurrencySubscription) error {
+	pairs := make([]string, 0, len(subscriptions))
 	for _, sub := range subscriptions {
-		if _, exists := currencyRates[sub.Currency]; !exists {
-			rate, _, err := GetCurrentRate(sub.Currency)
-			if err != nil {
-				log.Errorf("Error fetching rate for %s: %v", sub.Currency, err)
-				continue
-			}
-			currencyRates[sub.Currency] = rate
+		pairs = append(pairs, sub.Pair())
+	}
+	if !run.due && !rateDataDates.HasUnseen(pairs) && !slices.ContainsFunc(subscriptions, rateDataDates.Changed) {
+		log.Debugf("No new rate data expected until %v, skipping %d subscriptions", rateDataDates.NextDue(), len(subscriptions))
+		return nil
+	}
+
+	run.fetchRates(subscriptions)
 
-			history, err := GetHistoricalRates(sub.Currency, 12)
-			if err != nil {
-				log.Errorf("Error fetching history for %s: %v", sub.Currency, err)
+	// Alerts on a data date already seen were evaluated then, unless they
+	// have been added or edited since.
+	evaluating := make([]schemas.CurrencySubscription, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		if _, exists := run.rates[sub.Pair()]; exists && (run.fresh[sub.Pair()] || rateDataDates.Changed(sub)) {
+			evaluating = append(evaluating, sub)
+		}
+	}
+	run.fetchHistories(evaluating)
+	run.evaluated += len(evaluating)
+
+	for _, sub := range evaluating {
+		currentRate := run.rates[sub.Pair()]
+		series := indicatorSeries(run.histories[sub.Pair()], run.quotes[sub.Pair()])
+		rearmed := sub.Rearm(currentRate)
+		if trigger, _ := alertTrigger(&sub, currentRate, series); trigger == "" {
+			if rearmed {
+				run.wg.Add(1)
+				go run.rearm(sub, currentRate)
 			} else {
-				currencyHistories[sub.Currency] = history
+				rateDataDates.Evaluated(sub)
 			}
+			continue
 		}
-	}
 
-	var wg sync.WaitGroup
+		run.wg.Add(1)
+		go run.notify(sub, currentRate, run.histories[sub.Pair()], series, run.quotes[sub.Pair()])
+	}
+	return nil
+}
 
+// fetchRates quotes the pairs in subscriptions that earlier pages of this run
+// have not already fetched, one batch call per base currency.
+func (run *alertRun) fetchRates(subscriptions []schemas.CurrencySubscription) {
+	currenciesByBase := make(map[string][]string)
 	for _, sub := range subscriptions {
-		currentRate, exists := currencyRates[sub.Currency]
-		if !exists {
+		if run.quoted[sub.Pair()] {
 			continue
 		}
+		run.quoted[sub.Pair()] = true
+		currenciesByBase[sub.Base()] = append(currenciesByBase[sub.Base()], sub.Currency)
+	}
 
-		shouldNotify := false
-		var thresholdToRemove *float64
+	for base, currencies := range currenciesByBase {
+		currencies = schemas.QuoteCurrencies(base, currencies)
+		if len(currencies) == 0 {
+			continue
+		}
+		quotes, err := GetCurrentRates(base, currencies)
+		if err != nil {
+			log.Errorf("Error fetching rates against %s: %v", base, err)
+			continue
+		}
 
-		if sub.ShouldNotifyForThreshold(currentRate) {
-			shouldNotify = true
-			if sub.ThresholdAbove != nil && currentRate >= *sub.ThresholdAbove {
-				thresholdToRemove = sub.ThresholdAbove
-			} else if sub.ThresholdBelow != nil && currentRate <= *sub.ThresholdBelow {
-				thresholdToRemove = sub.ThresholdBelow
+		for _, currency := range currencies {
+			pair := currency + "/" + base
+			quote, ok := quotes[currency]
+			if !ok {
+				log.Errorf("Error fetching rate for %s: rate not available", pair)
+				continue
+			}
+			run.quotes[pair] = quote
+			if run.oldestDate == "" || quote.Date < run.oldestDate {
+				run.oldestDate = quote.Date
+			}
+			if quote.IsDisputed() && utils.RateCrossCheckMode == CrossCheckModeSuppress {
+				log.Warnf("Suppressing alerts for %s: %s and %s disagree by %.2f%%",
+					pair, quote.Source, quote.CrossCheck.Source, quote.CrossCheck.Deviation*100)
+				continue
+			}
+			run.rates[pair] = quote.Rate
+			if rateDataDates.IsNew(pair, quote.Date) {
+				run.fresh[pair] = true
+			} else {
+				log.Debugf("No new data for %s since %s, only evaluating changed alerts", pair, quote.Date)
 			}
 		}
+	}
+}
 
-		if sub.ShouldNotifyForInterval(currentRate) {
-			shouldNotify = true
+// fetchHistories loads the rate history of the pairs in subscriptions that
+// earlier pages of this run have not already loaded, one batch call per base
+// currency.
+func (run *alertRun) fetchHistories(subscriptions []schemas.CurrencySubscription) {
+	currenciesByBase := make(map[string][]string)
+	for _, sub := range subscriptions {
+		if run.loaded[sub.Pair()] {
+			continue
 		}
+		run.loaded[sub.Pair()] = true
+		currenciesByBase[sub.Base()] = append(currenciesByBase[sub.Base()], sub.Currency)
+	}
 
-		if !shouldNotify {
+	for base, currencies := range currenciesByBase {
+		histories, err := GetHistoricalRatesForCurrencies(base, currencies, 12)
+		if err != nil {
+			log.Errorf("Error fetching history against %s: %v", base, err)
 			continue
 		}
+		for currency, history := range histories {
+			run.histories[currency+"/"+base] = history
+		}
+	}
+}
 
-		wg.Add(1)
-		go func(s schemas.CurrencySubscription, rate float64, threshold *float64) {
-			defer wg.Done()
+// indicatorSeries returns the rate history with the latest quote appended
+// when the history does not reach its data date yet, so that indicator alerts
+// are evaluated on the data that triggered the run.
+func indicatorSeries(history []schemas.HistoricalRate, quote *schemas.ExchangeRate) []schemas.HistoricalRate {
+	if quote == nil {
+		return history
+	}
+	date, err := time.Parse("2006-01-02", quote.Date)
+	if err != nil || (len(history) > 0 && !history[len(history)-1].Date.Before(date)) {
+		return history
+	}
+	series := make([]schemas.HistoricalRate, len(history), len(history)+1)
+	copy(series, history)
+	return append(series, schemas.HistoricalRate{Date: date, Rate: quote.Rate})
+}
 
-			history := currencyHistories[s.Currency]
-			chartBuf, err := GenerateExchangeRateChart(history, s.Currency)
-			if err != nil {
-				log.Errorf("Error generating chart for %s: %v", s.Currency, err)
-			}
+// alertTrigger extends Trigger with the alert's indicator, which needs the
+// rate history. It also returns the indicator signal when that is what fired.
+// An indicator signal is sent once per data date.
+func alertTrigger(s *schemas.CurrencySubscription, rate float64, series []schemas.HistoricalRate) (string, string) {
+	if trigger := s.Trigger(rate); trigger != "" {
+		return trigger, ""
+	}
+	if s.Indicator == nil || len(series) == 0 || s.Indicator.LastSignal == seriesDate(series) {
+		return "", ""
+	}
+	if signal, ok := indicators.Evaluate(*s.Indicator, series); ok {
+		return schemas.TriggerIndicator, signal
+	}
+	return "", ""
+}
 
-			if chartBuf != nil {
-				photoFileBytes := tgbotapi.FileBytes{
-					Name:  "chart",
-					Bytes: *chartBuf,
-				}
-				photoConfig := tgbotapi.NewPhoto(s.ChatID, photoFileBytes)
-				photoConfig.Caption = s.GetNotificationMessage(rate, history)
-				photoConfig.ParseMode = "Markdown"
-
-				if _, err := bot.Send(photoConfig); err != nil {
-					log.Errorf("Error sending notification to chat %d: %v", s.ChatID, err)
-					return
-				}
-			} else {
-				msg := tgbotapi.NewMessage(s.ChatID, s.GetNotificationMessage(rate, history))
-				msg.ParseMode = "Markdown"
-				if _, err := bot.Send(msg); err != nil {
-					log.Errorf("Error sending notification to chat %d: %v", s.ChatID, err)
-					return
-				}
-			}
+func seriesDate(series []schemas.HistoricalRate) string {
+	return series[len(series)-1].Date.Format("2006-01-02")
+}
 
-			s.LastNotifiedRate = rate
-			s.LastNotificationTime = time.Now().In(timezone)
+func (run *alertRun) notify(s schemas.CurrencySubscription, rate float64, history, series []schemas.HistoricalRate, quote *schemas.ExchangeRate) {
+	defer run.wg.Done()
 
-			if threshold != nil {
-				s.ThresholdAbove = nil
-				s.ThresholdBelow = nil
+	s, claimed, trigger, ok := run.claim(context.Background(), s, rate, series)
+	if !ok {
+		return
+	}
 
-				if s.ThresholdAbove == nil && s.ThresholdBelow == nil && s.Interval == nil {
-					s.Enabled = false
-				}
-			}
+	message := s.GetNotificationMessage(rate, history)
+	if trigger == schemas.TriggerIndicator {
+		_, signal := alertTrigger(&s, rate, series)
+		message += "📐 " + signal + "\n"
+	}
+	message += FormatDataDate(quote) + FormatCrossCheckWarning(quote)
+	chartBuf, err := GenerateExchangeRateChart(history, s.Base(), s.Currency)
+	if err != nil {
+		log.Errorf("Error generating chart for %s: %v", s.Pair(), err)
+	}
 
-			if err := s.Update(); err != nil {
-				log.Errorf("Error updating subscription: %v", err)
-			}
+	var sent tgbotapi.Message
+	if chartBuf != nil {
+		photoFileBytes := tgbotapi.FileBytes{
+			Name:  "chart",
+			Bytes: *chartBuf,
+		}
+		photoConfig := tgbotapi.NewPhoto(s.ChatID, photoFileBytes)
+		photoConfig.Caption = message
+		photoConfig.ParseMode = "Markdown"
+		sent, err = run.bot.Send(photoConfig)
+	} else {
+		msg := tgbotapi.NewMessage(s.ChatID, message)
+		msg.ParseMode = "Markdown"
+		sent, err = run.bot.Send(msg)
+	}
+
+	recordNotification(s, rate, trigger, quote, sent.MessageID, err)
+	if err != nil {
+		log.Errorf("Error sending notification to chat %d: %v", s.ChatID, err)
+		run.fail(s)
+		release(context.Background(), s, claimed)
+		return
+	}
+
+	rateDataDates.Evaluated(claimed)
+	run.sent.Add(1)
+	log.Infof("Sent notification to chat %d for %s at rate %.4f", s.ChatID, s.Pair(), rate)
+}
+
+const maxClaimAttempts = 3
 
-			log.Infof("Sent notification to chat %d for %s at rate %.4f", s.ChatID, s.Currency, rate)
-		}(sub, currentRate, thresholdToRemove)
+// claim marks s as notified at rate before the message goes out, using the
+// subscription's version so that a concurrent edit is never overwritten. On
+// a conflict the latest copy of the alert is re-evaluated: an alert that was
+// removed, disabled or no longer triggers is skipped. It returns the copy
+// that triggered, the stored copy after the claim, and the trigger.
+func (run *alertRun) claim(ctx context.Context, s schemas.CurrencySubscription, rate float64, series []schemas.HistoricalRate) (schemas.CurrencySubscription, schemas.CurrencySubscription, string, bool) {
+	for attempt := 1; ; attempt++ {
+		s.Rearm(rate)
+		trigger, _ := alertTrigger(&s, rate, series)
+		if trigger == "" {
+			log.Infof("Skipping alert %s for %s, it no longer triggers at %.4f after being edited", s.ShortID(), s.Pair(), rate)
+			rateDataDates.Evaluated(s)
+			return s, s, "", false
+		}
+
+		claimed := s
+		claimed.MarkNotified(rate, trigger, time.Now().In(run.timezone))
+		if trigger == schemas.TriggerIndicator {
+			indicator := *claimed.Indicator
+			indicator.LastSignal = seriesDate(series)
+			claimed.Indicator = &indicator
+		}
+		err := store.Subscriptions.Update(ctx, &claimed)
+		switch {
+		case err == nil:
+			return s, claimed, trigger, true
+		case errors.Is(err, store.ErrAlertNotFound):
+			log.Infof("Skipping alert %s for %s, it was removed", s.ShortID(), s.Pair())
+			return s, s, "", false
+		case !errors.Is(err, store.ErrVersionConflict) || attempt == maxClaimAttempts:
+			log.Errorf("Error updating subscription: %v", err)
+			run.fail(s)
+			return s, s, "", false
+		}
+
+		latest, err := store.Subscriptions.Get(ctx, s.ID)
+		if err != nil {
+			log.Errorf("Error reloading subscription %s: %v", s.ID, err)
+			run.fail(s)
+			return s, s, "", false
+		}
+		if latest == nil || !latest.Enabled {
+			log.Infof("Skipping alert %s for %s, it was removed", s.ShortID(), s.Pair())
+			return s, s, "", false
+		}
+		s = *latest
 	}
+}
+
+// rearm stores a repeating threshold that the rate has moved back away from,
+// so that it can fire again. A conflicting edit is left for the next run to
+// re-evaluate.
+func (run *alertRun) rearm(s schemas.CurrencySubscription, rate float64) {
+	defer run.wg.Done()
 
-	wg.Wait()
+	err := store.Subscriptions.Update(context.Background(), &s)
+	switch {
+	case err == nil:
+		rateDataDates.Evaluated(s)
+		log.Infof("Re-armed alert %s for %s at rate %.4f", s.ShortID(), s.Pair(), rate)
+	case errors.Is(err, store.ErrVersionConflict) || errors.Is(err, store.ErrAlertNotFound):
+		log.Infof("Not re-arming alert %s for %s, it was changed during this run", s.ShortID(), s.Pair())
+	default:
+		log.Errorf("Error re-arming subscription %s: %v", s.ID, err)
+		run.fail(s)
+	}
+}
+
+// fail keeps the data date of s unseen and s itself marked as changed, so the
+// next run evaluates it again.
+func (run *alertRun) fail(s schemas.CurrencySubscription) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+	run.failed[s.Pair()] = true
+	rateDataDates.Forget(s.ID)
+}
+
+// release undoes a claim after the notification could not be sent so that
+// the alert is retried on the next run. If the alert was edited since the
+// claim, the edit wins.
+func release(ctx context.Context, original, claimed schemas.CurrencySubscription) {
+	original.Version = claimed.Version
+	err := store.Subscriptions.Update(ctx, &original)
+	switch {
+	case err == nil:
+	case errors.Is(err, store.ErrVersionConflict) || errors.Is(err, store.ErrAlertNotFound):
+		log.Infof("Not restoring alert %s for %s, it was changed after the failed send", original.ShortID(), original.Pair())
+	default:
+		log.Errorf("Error restoring subscription %s: %v", original.ID, err)
+	}
+}
+
+func recordNotification(s schemas.CurrencySubscription, rate float64, trigger string, quote *schemas.ExchangeRate, messageID int, sendErr error) {
+	notification := &schemas.Notification{
+		SubscriptionID: s.ID,
+		ChatID:         s.ChatID,
+		BaseCurrency:   s.Base(),
+		Currency:       s.Currency,
+		Rate:           rate,
+		Trigger:        trigger,
+		MessageID:      messageID,
+		Status:         schemas.NotificationDelivered,
+		SentAt:         time.Now().UTC(),
+	}
+	if quote != nil {
+		notification.DataDate = quote.Date
+	}
+	if sendErr != nil {
+		notification.Status = schemas.NotificationFailed
+		notification.Error = sendErr.Error()
+	}
+	if err := store.Notifications.Create(context.Background(), notification); err != nil {
+		log.Errorf("Error recording notification for chat %d: %v", s.ChatID, err)
+	}
 }
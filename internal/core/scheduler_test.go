@@ -0,0 +1,363 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/store"
+	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSender struct {
+	mu   sync.Mutex
+	sent []tgbotapi.Chattable
+	err  error
+}
+
+func (s *recordingSender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return tgbotapi.Message{}, s.err
+	}
+	s.sent = append(s.sent, c)
+	return tgbotapi.Message{MessageID: len(s.sent)}, nil
+}
+
+func useMemoryStore(t *testing.T) {
+	subscriptions, chatSettings, notifications := store.Subscriptions, store.ChatSettings, store.Notifications
+	require.NoError(t, store.Configure(store.BackendMemory))
+	originalDates := rateDataDates
+	rateDataDates = newDataDateTracker()
+	t.Cleanup(func() {
+		store.Subscriptions, store.ChatSettings, store.Notifications = subscriptions, chatSettings, notifications
+		rateDataDates = originalDates
+	})
+}
+
+func TestCheckAndNotify(t *testing.T) {
+	useMemoryStore(t)
+	useRateProvider(t, &stubRateProvider{
+		rates:   map[string]float64{"USD": 1.42, "EUR": 1.45},
+		history: dailyRates(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 30, 1.4),
+	})
+	ctx := context.Background()
+
+	above, higher, below, interval := 1.40, 1.45, 1.30, 0.05
+	triggered, _, err := store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above})
+	require.NoError(t, err)
+	pending, _, err := store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &higher})
+	require.NoError(t, err)
+	quiet, _, err := store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 2, BaseCurrency: "SGD", Currency: "EUR", ThresholdBelow: &below, Interval: &interval})
+	require.NoError(t, err)
+	quiet.LastNotifiedRate = 1.44
+	require.NoError(t, store.Subscriptions.Update(ctx, quiet))
+
+	sender := &recordingSender{}
+	checkAndNotify(sender, time.UTC)
+
+	require.Len(t, sender.sent, 1)
+	photo, ok := sender.sent[0].(tgbotapi.PhotoConfig)
+	require.True(t, ok)
+	assert.Equal(t, int64(1), photo.ChatID)
+	assert.Contains(t, photo.Caption, "USD/SGD Rate Alert")
+
+	sub, err := store.Subscriptions.Get(ctx, triggered.ID)
+	require.NoError(t, err)
+	assert.False(t, sub.Enabled)
+	assert.Nil(t, sub.ThresholdAbove)
+	assert.Equal(t, 1.42, sub.LastNotifiedRate)
+
+	sub, err = store.Subscriptions.Get(ctx, pending.ID)
+	require.NoError(t, err)
+	assert.True(t, sub.Enabled)
+	assert.Equal(t, higher, *sub.ThresholdAbove)
+
+	sub, err = store.Subscriptions.Get(ctx, quiet.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1.44, sub.LastNotifiedRate)
+
+	checkAndNotify(sender, time.UTC)
+	assert.Len(t, sender.sent, 1)
+}
+
+func TestCheckAndNotify_WalksEveryPage(t *testing.T) {
+	useMemoryStore(t)
+	pageSize := utils.SubscriptionPageSize
+	utils.SubscriptionPageSize = 2
+	t.Cleanup(func() { utils.SubscriptionPageSize = pageSize })
+	provider := &countingProvider{stubRateProvider: stubRateProvider{rates: map[string]float64{"USD": 1.42}}}
+	useRateProvider(t, provider)
+
+	above := 1.40
+	for chatID := int64(1); chatID <= 5; chatID++ {
+		_, _, err := store.AddSubscription(context.Background(), &schemas.CurrencySubscription{ChatID: chatID, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above})
+		require.NoError(t, err)
+	}
+
+	sender := &recordingSender{}
+	checkAndNotify(sender, time.UTC)
+
+	assert.Len(t, sender.sent, 5)
+	assert.Equal(t, int32(2), provider.calls.Load())
+	pages, err := store.ForEachActivePage(context.Background(), 2, func([]schemas.CurrencySubscription) error { return nil })
+	require.NoError(t, err)
+	assert.Equal(t, 0, pages)
+}
+
+func TestCheckAndNotify_RecordsNotifications(t *testing.T) {
+	useMemoryStore(t)
+	useRateProvider(t, &stubRateProvider{rates: map[string]float64{"USD": 1.42, "EUR": 1.25}})
+	ctx := context.Background()
+
+	above, below := 1.40, 1.30
+	delivered, _, err := store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above})
+	require.NoError(t, err)
+	checkAndNotify(&recordingSender{}, time.UTC)
+
+	failed, _, err := store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "EUR", ThresholdBelow: &below})
+	require.NoError(t, err)
+	rateDataDates = newDataDateTracker()
+	checkAndNotify(&recordingSender{err: errors.New("bot was blocked by the user")}, time.UTC)
+
+	notifications, err := store.Notifications.ListByChat(ctx, 1, "", 10)
+	require.NoError(t, err)
+	require.Len(t, notifications, 2)
+
+	assert.Equal(t, failed.ID, notifications[0].SubscriptionID)
+	assert.Equal(t, schemas.TriggerBelow, notifications[0].Trigger)
+	assert.Equal(t, schemas.NotificationFailed, notifications[0].Status)
+	assert.Contains(t, notifications[0].Error, "blocked")
+
+	assert.Equal(t, delivered.ID, notifications[1].SubscriptionID)
+	assert.Equal(t, schemas.TriggerAbove, notifications[1].Trigger)
+	assert.Equal(t, schemas.NotificationDelivered, notifications[1].Status)
+	assert.Equal(t, 1.42, notifications[1].Rate)
+	assert.Equal(t, 1, notifications[1].MessageID)
+	assert.NotEmpty(t, notifications[1].DataDate)
+
+	sub, err := store.Subscriptions.Get(ctx, failed.ID)
+	require.NoError(t, err)
+	assert.True(t, sub.Enabled)
+}
+
+// editingProvider runs edit the first time rates are fetched, after the
+// scheduler has loaded the subscriptions it is about to evaluate.
+type editingProvider struct {
+	stubRateProvider
+	once sync.Once
+	edit func()
+}
+
+func (p *editingProvider) LatestRates(base string, currencies []string) (map[string]*schemas.ExchangeRate, error) {
+	p.once.Do(p.edit)
+	return p.stubRateProvider.LatestRates(base, currencies)
+}
+
+func TestCheckAndNotify_ReevaluatesEditedAlerts(t *testing.T) {
+	useMemoryStore(t)
+	ctx := context.Background()
+
+	above, raised, below := 1.40, 1.50, 1.45
+	edited, _, err := store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above})
+	require.NoError(t, err)
+	retargeted, _, err := store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 2, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above})
+	require.NoError(t, err)
+	removed, _, err := store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 3, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above})
+	require.NoError(t, err)
+
+	useRateProvider(t, &editingProvider{
+		stubRateProvider: stubRateProvider{rates: map[string]float64{"USD": 1.42}},
+		edit: func() {
+			sub, _ := store.Subscriptions.Get(ctx, edited.ID)
+			sub.ThresholdAbove = &raised
+			require.NoError(t, store.Subscriptions.Update(ctx, sub))
+
+			sub, _ = store.Subscriptions.Get(ctx, retargeted.ID)
+			sub.ThresholdAbove, sub.ThresholdBelow = nil, &below
+			require.NoError(t, store.Subscriptions.Update(ctx, sub))
+
+			require.NoError(t, store.Subscriptions.Delete(ctx, removed.ID))
+		},
+	})
+
+	sender := &recordingSender{}
+	checkAndNotify(sender, time.UTC)
+
+	require.Len(t, sender.sent, 1)
+	msg := sender.sent[0].(tgbotapi.MessageConfig)
+	assert.Equal(t, int64(2), msg.ChatID)
+	assert.Contains(t, msg.Text, "Below 1.4500")
+
+	sub, err := store.Subscriptions.Get(ctx, edited.ID)
+	require.NoError(t, err)
+	assert.True(t, sub.Enabled)
+	assert.Equal(t, raised, *sub.ThresholdAbove)
+
+	sub, err = store.Subscriptions.Get(ctx, retargeted.ID)
+	require.NoError(t, err)
+	assert.False(t, sub.Enabled)
+	assert.Equal(t, 1.42, sub.LastNotifiedRate)
+
+	sub, err = store.Subscriptions.Get(ctx, removed.ID)
+	require.NoError(t, err)
+	assert.Nil(t, sub)
+}
+
+func TestCheckAndNotify_RepeatingThresholdRearms(t *testing.T) {
+	useMemoryStore(t)
+	provider := &stubRateProvider{rates: map[string]float64{"USD": 1.41}}
+	useRateProvider(t, provider)
+	ctx := context.Background()
+
+	above, band := 1.40, 0.01
+	sub, _, err := store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above, Repeat: true, Hysteresis: &band})
+	require.NoError(t, err)
+
+	sender := &recordingSender{}
+	for _, rate := range []float64{1.41, 1.42, 1.395, 1.41, 1.385, 1.40} {
+		provider.rates["USD"] = rate
+		rateDataDates = newDataDateTracker()
+		checkAndNotify(sender, time.UTC)
+	}
+
+	assert.Len(t, sender.sent, 2)
+	got, err := store.Subscriptions.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.True(t, got.Enabled)
+	assert.Equal(t, schemas.TriggerAbove, got.Triggered)
+	assert.Equal(t, 1.40, got.LastNotifiedRate)
+}
+
+func TestCheckAndNotify_Ladder(t *testing.T) {
+	useMemoryStore(t)
+	provider := &stubRateProvider{rates: map[string]float64{"USD": 1.342}}
+	useRateProvider(t, provider)
+	ctx := context.Background()
+
+	sub, _, err := store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "USD",
+		Ladder: []float64{1.34, 1.345, 1.35, 1.355}, LadderDirection: schemas.TriggerAbove})
+	require.NoError(t, err)
+
+	sender := &recordingSender{}
+	for _, rate := range []float64{1.342, 1.343, 1.351, 1.356} {
+		provider.rates["USD"] = rate
+		rateDataDates = newDataDateTracker()
+		checkAndNotify(sender, time.UTC)
+	}
+
+	require.Len(t, sender.sent, 3)
+	assert.Contains(t, sender.sent[0].(tgbotapi.MessageConfig).Text, "crossed 1.3400 SGD, 3 remaining")
+	assert.Contains(t, sender.sent[1].(tgbotapi.MessageConfig).Text, "crossed 1.3450, 1.3500 SGD, 1 remaining")
+	assert.Contains(t, sender.sent[2].(tgbotapi.MessageConfig).Text, "that was the last rung")
+
+	got, err := store.Subscriptions.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Ladder)
+	assert.False(t, got.Enabled)
+
+	notifications, err := store.Notifications.ListByChat(ctx, 1, "", 10)
+	require.NoError(t, err)
+	require.Len(t, notifications, 3)
+	assert.Equal(t, schemas.TriggerLadder, notifications[0].Trigger)
+}
+
+func TestCheckAndNotify_IndicatorSignalsOncePerDataDate(t *testing.T) {
+	useMemoryStore(t)
+	history := dailyRates(time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC), 10, 1.30)
+	useRateProvider(t, &stubRateProvider{rates: map[string]float64{"USD": 1.40}, history: history})
+	ctx := context.Background()
+
+	sub, _, err := store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "USD",
+		Indicator: &schemas.IndicatorAlert{Kind: schemas.IndicatorSMA, Fast: 2, Slow: 5, Direction: schemas.TriggerAbove}})
+	require.NoError(t, err)
+
+	sender := &recordingSender{}
+	for range 2 {
+		rateDataDates = newDataDateTracker()
+		checkAndNotify(sender, time.UTC)
+	}
+
+	require.Len(t, sender.sent, 1)
+	assert.Contains(t, sender.sent[0].(tgbotapi.PhotoConfig).Caption, "📐 2-day SMA crossed above 5-day SMA (1.3500 vs 1.3200)")
+
+	got, err := store.Subscriptions.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.True(t, got.Enabled)
+	assert.Equal(t, "2026-02-20", got.Indicator.LastSignal)
+
+	notifications, err := store.Notifications.ListByChat(ctx, 1, "", 10)
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, schemas.TriggerIndicator, notifications[0].Trigger)
+}
+
+func TestCheckAndNotify_RetriesFailedSends(t *testing.T) {
+	useMemoryStore(t)
+	useRateProvider(t, &stubRateProvider{rates: map[string]float64{"USD": 1.42}})
+	ctx := context.Background()
+
+	above := 1.40
+	sub, _, err := store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above})
+	require.NoError(t, err)
+
+	checkAndNotify(&recordingSender{err: errors.New("Too Many Requests")}, time.UTC)
+	assert.True(t, rateDataDates.IsNew("USD/SGD", "2026-02-20"), "the data date stays unseen after a failed send")
+
+	sender := &recordingSender{}
+	checkAndNotify(sender, time.UTC)
+	require.Len(t, sender.sent, 1)
+	assert.False(t, rateDataDates.IsNew("USD/SGD", "2026-02-20"))
+
+	got, err := store.Subscriptions.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Enabled)
+}
+
+func TestCheckAndNotify_EvaluatesChangedAlertsOnSeenDates(t *testing.T) {
+	useMemoryStore(t)
+	provider := &countingProvider{
+		stubRateProvider: stubRateProvider{rates: map[string]float64{"USD": 1.42}},
+		next:             time.Now().Add(24 * time.Hour),
+	}
+	useRateProvider(t, provider)
+	ctx := context.Background()
+
+	above, lower := 1.45, 1.41
+	edited, _, err := store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above})
+	require.NoError(t, err)
+	untouched, _, err := store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 2, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above})
+	require.NoError(t, err)
+
+	sender := &recordingSender{}
+	checkAndNotify(sender, time.UTC)
+	assert.Empty(t, sender.sent)
+	assert.False(t, rateDataDates.Due(time.Now()))
+
+	calls := provider.calls.Load()
+	checkAndNotify(sender, time.UTC)
+	assert.Equal(t, calls, provider.calls.Load(), "nothing changed, so nothing is fetched")
+
+	sub, err := store.Subscriptions.Get(ctx, edited.ID)
+	require.NoError(t, err)
+	sub.ThresholdAbove = &lower
+	require.NoError(t, store.Subscriptions.Update(ctx, sub))
+	_, _, err = store.AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 3, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &lower})
+	require.NoError(t, err)
+
+	checkAndNotify(sender, time.UTC)
+	require.Len(t, sender.sent, 2)
+	chats := []int64{sender.sent[0].(tgbotapi.MessageConfig).ChatID, sender.sent[1].(tgbotapi.MessageConfig).ChatID}
+	assert.ElementsMatch(t, []int64{1, 3}, chats)
+
+	got, err := store.Subscriptions.Get(ctx, untouched.ID)
+	require.NoError(t, err)
+	assert.True(t, got.Enabled)
+}
@@ -0,0 +1,402 @@
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/core/indicators"
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
+)
+
+const (
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+)
+
+const subscriptionExportVersion = 1
+
+var csvExportHeader = []string{"kind", "base_currency", "currency", "threshold_above", "threshold_below", "interval",
+	"threshold_above_percent", "threshold_below_percent", "interval_percent", "reference_rate",
+	"repeat", "hysteresis", "hysteresis_percent", "ladder", "ladder_direction", "indicator"}
+
+type ExportedAlert struct {
+	BaseCurrency   string   `json:"base_currency"`
+	Currency       string   `json:"currency"`
+	ThresholdAbove *float64 `json:"threshold_above,omitempty"`
+	ThresholdBelow *float64 `json:"threshold_below,omitempty"`
+	Interval       *float64 `json:"interval,omitempty"`
+
+	ThresholdAbovePercent *float64 `json:"threshold_above_percent,omitempty"`
+	ThresholdBelowPercent *float64 `json:"threshold_below_percent,omitempty"`
+	IntervalPercent       *float64 `json:"interval_percent,omitempty"`
+	ReferenceRate         float64  `json:"reference_rate,omitempty"`
+
+	Repeat            bool     `json:"repeat,omitempty"`
+	Hysteresis        *float64 `json:"hysteresis,omitempty"`
+	HysteresisPercent *float64 `json:"hysteresis_percent,omitempty"`
+
+	Ladder          []float64 `json:"ladder,omitempty"`
+	LadderDirection string    `json:"ladder_direction,omitempty"`
+
+	Indicator *schemas.IndicatorAlert `json:"indicator,omitempty"`
+}
+
+// SubscriptionExport is the document /fx_export sends and /fx_import reads
+// back: the chat's home currency and its active alerts.
+type SubscriptionExport struct {
+	Version      int             `json:"version"`
+	BaseCurrency string          `json:"base_currency"`
+	Alerts       []ExportedAlert `json:"alerts"`
+}
+
+// ImportRow is one alert read from an import document. Row is the 1-based
+// alert number in JSON documents and the line number in CSV documents.
+type ImportRow struct {
+	Row   int
+	Alert ExportedAlert
+	Err   error
+}
+
+func NewSubscriptionExport(settings schemas.ChatSettings, subscriptions []schemas.CurrencySubscription) SubscriptionExport {
+	export := SubscriptionExport{Version: subscriptionExportVersion, BaseCurrency: settings.Base(), Alerts: []ExportedAlert{}}
+	for _, sub := range subscriptions {
+		if !sub.Enabled {
+			continue
+		}
+		export.Alerts = append(export.Alerts, ExportedAlert{
+			BaseCurrency:   sub.Base(),
+			Currency:       sub.Currency,
+			ThresholdAbove: sub.ThresholdAbove,
+			ThresholdBelow: sub.ThresholdBelow,
+			Interval:       sub.Interval,
+
+			ThresholdAbovePercent: sub.ThresholdAbovePercent,
+			ThresholdBelowPercent: sub.ThresholdBelowPercent,
+			IntervalPercent:       sub.IntervalPercent,
+			ReferenceRate:         sub.ReferenceRate,
+
+			Repeat:            sub.Repeat,
+			Hysteresis:        sub.Hysteresis,
+			HysteresisPercent: sub.HysteresisPercent,
+
+			Ladder:          sub.Ladder,
+			LadderDirection: sub.LadderDirection,
+
+			Indicator: exportIndicator(sub.Indicator),
+		})
+	}
+	return export
+}
+
+// exportIndicator copies an indicator alert without its last signal, which
+// only applies to the alert it was sent for.
+func exportIndicator(indicator *schemas.IndicatorAlert) *schemas.IndicatorAlert {
+	if indicator == nil {
+		return nil
+	}
+	exported := *indicator
+	exported.LastSignal = ""
+	return &exported
+}
+
+func (e SubscriptionExport) Encode(format string) ([]byte, error) {
+	switch format {
+	case ExportFormatJSON:
+		return json.MarshalIndent(e, "", "  ")
+	case ExportFormatCSV:
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Write(csvExportHeader)
+		settings := make([]string, len(csvExportHeader))
+		settings[0], settings[1] = "settings", e.BaseCurrency
+		w.Write(settings)
+		for _, a := range e.Alerts {
+			var reference string
+			if a.ReferenceRate > 0 {
+				reference = strconv.FormatFloat(a.ReferenceRate, 'f', -1, 64)
+			}
+			w.Write([]string{"alert", a.BaseCurrency, a.Currency, formatOptionalFloat(a.ThresholdAbove), formatOptionalFloat(a.ThresholdBelow), formatOptionalFloat(a.Interval),
+				formatOptionalFloat(a.ThresholdAbovePercent), formatOptionalFloat(a.ThresholdBelowPercent), formatOptionalFloat(a.IntervalPercent), reference,
+				strconv.FormatBool(a.Repeat), formatOptionalFloat(a.Hysteresis), formatOptionalFloat(a.HysteresisPercent),
+				formatRungs(a.Ladder), a.LadderDirection, formatIndicator(a.Indicator)})
+		}
+		w.Flush()
+		return buf.Bytes(), w.Error()
+	default:
+		return nil, fmt.Errorf("unknown export format: %s", format)
+	}
+}
+
+// DetectExportFormat picks the format of an import document from its file
+// name, falling back to its content.
+func DetectExportFormat(fileName string, data []byte) string {
+	switch {
+	case strings.HasSuffix(strings.ToLower(fileName), ".csv"):
+		return ExportFormatCSV
+	case strings.HasSuffix(strings.ToLower(fileName), ".json"):
+		return ExportFormatJSON
+	case strings.HasPrefix(strings.TrimSpace(string(data)), "{"):
+		return ExportFormatJSON
+	default:
+		return ExportFormatCSV
+	}
+}
+
+// ParseSubscriptionImport reads an import document. Errors in individual
+// alerts are reported on their row; the returned error is only set when the
+// document as a whole cannot be read.
+func ParseSubscriptionImport(format string, data []byte) (baseCurrency string, rows []ImportRow, err error) {
+	switch format {
+	case ExportFormatJSON:
+		return parseJSONImport(data)
+	case ExportFormatCSV:
+		return parseCSVImport(data)
+	default:
+		return "", nil, fmt.Errorf("unknown import format: %s", format)
+	}
+}
+
+func parseJSONImport(data []byte) (string, []ImportRow, error) {
+	var doc struct {
+		Version      int               `json:"version"`
+		BaseCurrency string            `json:"base_currency"`
+		Alerts       []json.RawMessage `json:"alerts"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return "", nil, fmt.Errorf("invalid JSON document: %w", err)
+	}
+	if doc.Version > subscriptionExportVersion {
+		return "", nil, fmt.Errorf("document version %d is newer than this bot supports", doc.Version)
+	}
+
+	rows := make([]ImportRow, 0, len(doc.Alerts))
+	for i, raw := range doc.Alerts {
+		row := ImportRow{Row: i + 1}
+		if err := json.Unmarshal(raw, &row.Alert); err != nil {
+			row.Err = fmt.Errorf("invalid alert: %w", err)
+		} else {
+			row.Err = ValidateExportedAlert(&row.Alert)
+		}
+		rows = append(rows, row)
+	}
+	return doc.BaseCurrency, rows, nil
+}
+
+func parseCSVImport(data []byte) (string, []ImportRow, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid CSV document: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"kind", "base_currency", "currency"} {
+		if _, ok := columns[name]; !ok {
+			return "", nil, fmt.Errorf("invalid CSV document: missing %q column", name)
+		}
+	}
+
+	var baseCurrency string
+	var rows []ImportRow
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := r.FieldPos(0)
+		if err != nil {
+			rows = append(rows, ImportRow{Row: line, Err: err})
+			continue
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		switch strings.ToLower(field("kind")) {
+		case "settings":
+			baseCurrency = field("base_currency")
+		case "alert":
+			row := ImportRow{Row: line, Alert: ExportedAlert{BaseCurrency: field("base_currency"), Currency: field("currency")}}
+			for _, target := range []struct {
+				column string
+				value  **float64
+			}{
+				{"threshold_above", &row.Alert.ThresholdAbove},
+				{"threshold_below", &row.Alert.ThresholdBelow},
+				{"interval", &row.Alert.Interval},
+				{"threshold_above_percent", &row.Alert.ThresholdAbovePercent},
+				{"threshold_below_percent", &row.Alert.ThresholdBelowPercent},
+				{"interval_percent", &row.Alert.IntervalPercent},
+				{"hysteresis", &row.Alert.Hysteresis},
+				{"hysteresis_percent", &row.Alert.HysteresisPercent},
+			} {
+				value, err := parseOptionalFloat(field(target.column))
+				if err != nil {
+					row.Err = fmt.Errorf("invalid %s: %w", target.column, err)
+					break
+				}
+				*target.value = value
+			}
+			if reference := field("reference_rate"); row.Err == nil && reference != "" {
+				if row.Alert.ReferenceRate, row.Err = strconv.ParseFloat(reference, 64); row.Err != nil {
+					row.Err = fmt.Errorf("invalid reference_rate: %w", row.Err)
+				}
+			}
+			if repeat := field("repeat"); row.Err == nil && repeat != "" {
+				if row.Alert.Repeat, row.Err = strconv.ParseBool(repeat); row.Err != nil {
+					row.Err = fmt.Errorf("invalid repeat: %w", row.Err)
+				}
+			}
+			if row.Err == nil {
+				row.Alert.LadderDirection = field("ladder_direction")
+				if row.Alert.Ladder, row.Err = parseRungs(field("ladder")); row.Err != nil {
+					row.Err = fmt.Errorf("invalid ladder: %w", row.Err)
+				}
+			}
+			if indicator := field("indicator"); row.Err == nil && indicator != "" {
+				parsed, err := indicators.ParseAlert(strings.Fields(indicator))
+				if err != nil {
+					row.Err = fmt.Errorf("invalid indicator: %w", err)
+				}
+				row.Alert.Indicator = &parsed
+			}
+			if row.Err == nil {
+				row.Err = ValidateExportedAlert(&row.Alert)
+			}
+			rows = append(rows, row)
+		default:
+			rows = append(rows, ImportRow{Row: line, Err: fmt.Errorf("unknown kind %q, expected settings or alert", field("kind"))})
+		}
+	}
+	return baseCurrency, rows, nil
+}
+
+// ValidateExportedAlert normalises the currency codes of a and checks that it
+// describes an alert the bot can evaluate.
+func ValidateExportedAlert(a *ExportedAlert) error {
+	a.BaseCurrency = strings.ToUpper(a.BaseCurrency)
+	a.Currency = strings.ToUpper(a.Currency)
+	if a.BaseCurrency == "" {
+		a.BaseCurrency = utils.DEFAULT_BASE_CURRENCY
+	}
+	for _, code := range []string{a.Currency, a.BaseCurrency} {
+		if !utils.IsCurrencySupported(code) {
+			return fmt.Errorf("unsupported currency %q", code)
+		}
+	}
+	if a.Currency == a.BaseCurrency {
+		return fmt.Errorf("%s cannot be quoted against itself", a.Currency)
+	}
+	if a.ThresholdAbove == nil && a.ThresholdBelow == nil && a.Interval == nil &&
+		a.ThresholdAbovePercent == nil && a.ThresholdBelowPercent == nil && a.IntervalPercent == nil && len(a.Ladder) == 0 && a.Indicator == nil {
+		return errors.New("no threshold_above, threshold_below, interval, ladder or indicator set")
+	}
+	if len(a.Ladder) > 0 {
+		if err := validateLadder(a.Ladder, a.LadderDirection); err != nil {
+			return err
+		}
+	}
+	if a.Indicator != nil {
+		if err := indicators.Validate(*a.Indicator); err != nil {
+			return fmt.Errorf("invalid indicator: %w", err)
+		}
+	}
+	for _, value := range []*float64{a.ThresholdAbove, a.ThresholdBelow, a.Interval} {
+		if value != nil && *value <= 0 {
+			return fmt.Errorf("%v is not a positive rate", *value)
+		}
+	}
+	if a.Hysteresis != nil && *a.Hysteresis <= 0 {
+		return fmt.Errorf("hysteresis %v is not positive", *a.Hysteresis)
+	}
+	for _, value := range []*float64{a.ThresholdAbovePercent, a.IntervalPercent, a.HysteresisPercent} {
+		if value != nil && *value <= 0 {
+			return fmt.Errorf("%v%% is not a positive percentage", *value)
+		}
+	}
+	if a.ThresholdBelowPercent != nil && (*a.ThresholdBelowPercent >= 0 || *a.ThresholdBelowPercent <= -100) {
+		return fmt.Errorf("threshold_below_percent %v is not between -100 and 0", *a.ThresholdBelowPercent)
+	}
+	if a.ReferenceRate < 0 {
+		return fmt.Errorf("reference_rate %v is negative", a.ReferenceRate)
+	}
+	return nil
+}
+
+func validateLadder(rungs []float64, direction string) error {
+	if direction != schemas.TriggerAbove && direction != schemas.TriggerBelow {
+		return fmt.Errorf("ladder_direction %q is not above or below", direction)
+	}
+	for i, rung := range rungs {
+		if rung <= 0 {
+			return fmt.Errorf("ladder rung %v is not a positive rate", rung)
+		}
+		if i > 0 && ((direction == schemas.TriggerAbove && rung <= rungs[i-1]) || (direction == schemas.TriggerBelow && rung >= rungs[i-1])) {
+			return fmt.Errorf("ladder rungs are not in %s order", direction)
+		}
+	}
+	return nil
+}
+
+// formatRungs writes a ladder into a single CSV field, separated by spaces.
+func formatRungs(rungs []float64) string {
+	values := make([]string, len(rungs))
+	for i, rung := range rungs {
+		values[i] = strconv.FormatFloat(rung, 'f', -1, 64)
+	}
+	return strings.Join(values, " ")
+}
+
+func parseRungs(s string) ([]float64, error) {
+	var rungs []float64
+	for _, field := range strings.Fields(s) {
+		rung, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, err
+		}
+		rungs = append(rungs, rung)
+	}
+	return rungs, nil
+}
+
+// formatIndicator writes an indicator alert into a single CSV field, in the
+// form /fx_indicator accepts.
+func formatIndicator(indicator *schemas.IndicatorAlert) string {
+	if indicator == nil {
+		return ""
+	}
+	return indicator.Args()
+}
+
+func formatOptionalFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+func parseOptionalFloat(s string) (*float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionExport_RoundTrip(t *testing.T) {
+	subscriptions := []schemas.CurrencySubscription{
+		{ID: "a", Currency: "USD", ThresholdAbove: float64Ptr(1.4), Enabled: true},
+		{ID: "b", BaseCurrency: "USD", Currency: "EUR", Interval: float64Ptr(0.005), Enabled: true},
+		{ID: "c", Currency: "JPY", ThresholdBelow: float64Ptr(0.009), Enabled: false},
+		{ID: "d", Currency: "GBP", ThresholdBelowPercent: float64Ptr(-3), IntervalPercent: float64Ptr(1), ReferenceRate: 1.7, Enabled: true},
+		{ID: "e", Currency: "USD", ThresholdAbove: float64Ptr(1.45), Repeat: true, HysteresisPercent: float64Ptr(0.5), Triggered: schemas.TriggerAbove, Enabled: true},
+		{ID: "f", Currency: "USD", Ladder: []float64{1.36, 1.355, 1.35}, LadderDirection: schemas.TriggerBelow, Enabled: true},
+		{ID: "g", Currency: "USD", Indicator: &schemas.IndicatorAlert{Kind: schemas.IndicatorRSI, Period: 14, Level: 70, Direction: schemas.TriggerAbove, LastSignal: "2026-02-20"}, Enabled: true},
+	}
+	export := NewSubscriptionExport(schemas.ChatSettings{ChatId: 1, BaseCurrency: "MYR"}, subscriptions)
+	require.Len(t, export.Alerts, 6)
+	assert.Empty(t, export.Alerts[5].Indicator.LastSignal)
+	assert.Equal(t, "2026-02-20", subscriptions[6].Indicator.LastSignal)
+
+	for _, format := range []string{ExportFormatJSON, ExportFormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			data, err := export.Encode(format)
+			require.NoError(t, err)
+			assert.Equal(t, format, DetectExportFormat("", data))
+
+			base, rows, err := ParseSubscriptionImport(format, data)
+			require.NoError(t, err)
+			assert.Equal(t, "MYR", base)
+			require.Len(t, rows, 6)
+			for i, row := range rows {
+				require.NoError(t, row.Err)
+				assert.Equal(t, export.Alerts[i], row.Alert)
+			}
+		})
+	}
+}
+
+func TestParseSubscriptionImport_ReportsRowErrors(t *testing.T) {
+	csvDoc := "kind,base_currency,currency,threshold_above,threshold_below,interval\n" +
+		"alert,sgd,usd,1.4,,\n" +
+		"alert,SGD,XYZ,1.4,,\n" +
+		"alert,SGD,EUR,abc,,\n" +
+		"alert,SGD,EUR,,,\n" +
+		"alert,SGD,SGD,1,,\n" +
+		"rule,SGD,EUR,1,,\n"
+	base, rows, err := ParseSubscriptionImport(ExportFormatCSV, []byte(csvDoc))
+	require.NoError(t, err)
+	assert.Empty(t, base)
+	require.Len(t, rows, 6)
+
+	require.NoError(t, rows[0].Err)
+	assert.Equal(t, ExportedAlert{BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: float64Ptr(1.4)}, rows[0].Alert)
+	assert.Equal(t, 3, rows[1].Row)
+	assert.ErrorContains(t, rows[1].Err, `unsupported currency "XYZ"`)
+	assert.ErrorContains(t, rows[2].Err, "invalid threshold_above")
+	assert.ErrorContains(t, rows[3].Err, "no threshold_above")
+	assert.ErrorContains(t, rows[4].Err, "against itself")
+	assert.ErrorContains(t, rows[5].Err, `unknown kind "rule"`)
+
+	jsonDoc := `{"version":1,"alerts":[{"currency":"USD","interval":0.01},{"currency":"EUR","threshold_below":-1},{"currency":42}]}`
+	_, rows, err = ParseSubscriptionImport(DetectExportFormat("alerts.txt", []byte(jsonDoc)), []byte(jsonDoc))
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	require.NoError(t, rows[0].Err)
+	assert.Equal(t, "SGD", rows[0].Alert.BaseCurrency)
+	assert.ErrorContains(t, rows[1].Err, "not a positive rate")
+	assert.ErrorContains(t, rows[2].Err, "invalid alert")
+
+	_, _, err = ParseSubscriptionImport(ExportFormatJSON, []byte(`{"version":2,"alerts":[]}`))
+	assert.Error(t, err)
+	_, _, err = ParseSubscriptionImport(ExportFormatCSV, []byte("currency\nUSD\n"))
+	assert.ErrorContains(t, err, `missing "kind" column`)
+}
@@ -0,0 +1,205 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+type TriangulatingProvider struct {
+	schemas.RateProvider
+	Pivots []string
+}
+
+func NewTriangulatingProvider(provider schemas.RateProvider, pivots []string) *TriangulatingProvider {
+	return &TriangulatingProvider{RateProvider: provider, Pivots: pivots}
+}
+
+// ValidatePivotCurrencies checks that provider quotes every pivot. The default
+// home currency is always accepted, as providers that quote against it do not
+// list it. If provider cannot list its currencies, the built-in list is used.
+func ValidatePivotCurrencies(provider schemas.RateProvider, pivots []string) error {
+	known := map[string]bool{utils.DEFAULT_BASE_CURRENCY: true}
+	provided, err := provider.SupportedCurrencies()
+	if err != nil {
+		log.Warnf("Error listing currencies from %s, checking pivot currencies against the built-in list: %v", provider.Name(), err)
+		for _, c := range utils.DefaultCurrencies {
+			known[c.Code] = true
+		}
+	}
+	for _, c := range provided {
+		known[strings.ToUpper(c.Code)] = true
+	}
+
+	var unknown []string
+	for _, pivot := range pivots {
+		if !known[pivot] {
+			unknown = append(unknown, pivot)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown pivot currencies %s, %s does not quote them", strings.Join(unknown, ", "), provider.Name())
+	}
+	return nil
+}
+
+// pivotLegs returns the currencies to request against pivot: every missing
+// currency except the pivot itself, which is worth 1 pivot, plus the base leg.
+func pivotLegs(pivot, base string, missing []string) []string {
+	if len(missing) == 0 {
+		return nil
+	}
+	legs := make([]string, 0, len(missing)+1)
+	for _, currency := range missing {
+		if currency != pivot {
+			legs = append(legs, currency)
+		}
+	}
+	return append(legs, base)
+}
+
+func (p *TriangulatingProvider) LatestRates(base string, currencies []string) (map[string]*schemas.ExchangeRate, error) {
+	currencies = schemas.QuoteCurrencies(base, currencies)
+
+	var errs []error
+	quotes, err := p.RateProvider.LatestRates(base, currencies)
+	if err != nil {
+		errs = append(errs, err)
+		quotes = make(map[string]*schemas.ExchangeRate)
+	}
+
+	for _, pivot := range p.Pivots {
+		missing := missingCurrencies(currencies, quotes)
+		legs := pivotLegs(pivot, base, missing)
+		if pivot == base || len(legs) == 0 {
+			continue
+		}
+
+		legQuotes, err := p.RateProvider.LatestRates(pivot, legs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("via %s: %w", pivot, err))
+			continue
+		}
+		baseLeg, ok := legQuotes[base]
+		if !ok {
+			errs = append(errs, fmt.Errorf("via %s: rate not available for currency: %s", pivot, base))
+			continue
+		}
+
+		for _, currency := range missing {
+			currencyLeg, ok := legQuotes[currency]
+			if currency == pivot {
+				currencyLeg = &schemas.ExchangeRate{Base: pivot, Currency: pivot, Rate: 1, Date: baseLeg.Date, Source: baseLeg.Source}
+				ok = true
+			}
+			if !ok {
+				errs = append(errs, fmt.Errorf("via %s: rate not available for currency: %s", pivot, currency))
+				continue
+			}
+			log.Debugf("Triangulated %s/%s via %s", currency, base, pivot)
+			quotes[currency] = crossQuote(pivot, base, currency, baseLeg, currencyLeg)
+		}
+	}
+
+	if len(quotes) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return quotes, nil
+}
+
+func crossQuote(pivot, base, currency string, baseLeg, currencyLeg *schemas.ExchangeRate) *schemas.ExchangeRate {
+	date := currencyLeg.Date
+	if baseLeg.Date < date {
+		date = baseLeg.Date
+	}
+
+	cross := &schemas.ExchangeRate{
+		Base:     base,
+		Currency: currency,
+		Rate:     currencyLeg.Rate / baseLeg.Rate,
+		Date:     date,
+		Source:   currencyLeg.Source,
+		Via:      pivot,
+	}
+	if currencyLeg.IsDisputed() {
+		cross.CrossCheck = currencyLeg.CrossCheck
+	} else if baseLeg.IsDisputed() {
+		cross.CrossCheck = baseLeg.CrossCheck
+	}
+	return cross
+}
+
+func (p *TriangulatingProvider) HistoricalRates(base string, currencies []string, start, end time.Time) (map[string][]schemas.HistoricalRate, error) {
+	currencies = schemas.QuoteCurrencies(base, currencies)
+
+	var errs []error
+	histories, err := p.RateProvider.HistoricalRates(base, currencies, start, end)
+	if err != nil {
+		errs = append(errs, err)
+		histories = make(map[string][]schemas.HistoricalRate)
+	}
+
+	for _, pivot := range p.Pivots {
+		missing := missingCurrencies(currencies, histories)
+		legs := pivotLegs(pivot, base, missing)
+		if pivot == base || len(legs) == 0 {
+			continue
+		}
+
+		legHistories, err := p.RateProvider.HistoricalRates(pivot, legs, start, end)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("via %s: %w", pivot, err))
+			continue
+		}
+		baseLeg, ok := legHistories[base]
+		if !ok {
+			errs = append(errs, fmt.Errorf("via %s: rate not available for currency: %s", pivot, base))
+			continue
+		}
+
+		baseRates := make(map[time.Time]float64, len(baseLeg))
+		for _, r := range baseLeg {
+			baseRates[r.Date] = r.Rate
+		}
+
+		for _, currency := range missing {
+			currencyLeg, ok := legHistories[currency]
+			if currency == pivot {
+				currencyLeg = make([]schemas.HistoricalRate, len(baseLeg))
+				for i, r := range baseLeg {
+					currencyLeg[i] = schemas.HistoricalRate{Date: r.Date, Rate: 1}
+				}
+				ok = true
+			}
+			if !ok {
+				errs = append(errs, fmt.Errorf("via %s: rate not available for currency: %s", pivot, currency))
+				continue
+			}
+
+			cross := make([]schemas.HistoricalRate, 0, len(currencyLeg))
+			for _, r := range currencyLeg {
+				baseRate, ok := baseRates[r.Date]
+				if !ok || baseRate == 0 {
+					continue
+				}
+				cross = append(cross, schemas.HistoricalRate{Date: r.Date, Rate: r.Rate / baseRate})
+			}
+			histories[currency] = cross
+		}
+	}
+
+	if len(histories) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return histories, nil
+}
+
+func (p *TriangulatingProvider) NextPublication(after time.Time) time.Time {
+	next, _ := schemas.NextPublicationOf(p.RateProvider, after)
+	return next
+}
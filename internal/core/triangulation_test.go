@@ -0,0 +1,126 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sgdOnlyProvider struct {
+	stubRateProvider
+	histories map[string][]schemas.HistoricalRate
+}
+
+func (p *sgdOnlyProvider) LatestRates(base string, currencies []string) (map[string]*schemas.ExchangeRate, error) {
+	if base != "SGD" {
+		return nil, fmt.Errorf("only SGD base supported")
+	}
+	return p.stubRateProvider.LatestRates(base, currencies)
+}
+
+func (p *sgdOnlyProvider) HistoricalRates(base string, currencies []string, start, end time.Time) (map[string][]schemas.HistoricalRate, error) {
+	if base != "SGD" {
+		return nil, fmt.Errorf("only SGD base supported")
+	}
+	histories := make(map[string][]schemas.HistoricalRate)
+	for _, currency := range currencies {
+		if rates, ok := p.histories[currency]; ok {
+			histories[currency] = rates
+		}
+	}
+	return histories, nil
+}
+
+func TestTriangulatingProvider_DirectQuote(t *testing.T) {
+	provider := NewTriangulatingProvider(&stubRateProvider{rates: map[string]float64{"USD": 1.35}}, []string{"SGD"})
+
+	quote, err := schemas.FetchLatestRate(provider, "SGD", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, 1.35, quote.Rate)
+	assert.Empty(t, quote.Via)
+}
+
+func TestTriangulatingProvider_CrossRateViaPivot(t *testing.T) {
+	base := &sgdOnlyProvider{stubRateProvider: stubRateProvider{rates: map[string]float64{"EUR": 1.45, "USD": 1.35}}}
+	provider := NewTriangulatingProvider(base, []string{"SGD"})
+
+	quote, err := schemas.FetchLatestRate(provider, "USD", "EUR")
+	require.NoError(t, err)
+	assert.InDelta(t, 1.45/1.35, quote.Rate, 1e-12)
+	assert.Equal(t, "USD", quote.Base)
+	assert.Equal(t, "EUR", quote.Currency)
+	assert.Equal(t, "SGD", quote.Via)
+}
+
+func TestTriangulatingProvider_NoUsablePivot(t *testing.T) {
+	base := &sgdOnlyProvider{stubRateProvider: stubRateProvider{rates: map[string]float64{"USD": 1.35}}}
+	provider := NewTriangulatingProvider(base, []string{"SGD"})
+
+	_, err := schemas.FetchLatestRate(provider, "USD", "EUR")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "via SGD")
+}
+
+func TestTriangulatingProvider_HistoricalCrossRates(t *testing.T) {
+	day1 := time.Date(2026, 2, 19, 0, 0, 0, 0, time.UTC)
+	day2 := time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC)
+	base := &sgdOnlyProvider{histories: map[string][]schemas.HistoricalRate{
+		"EUR": {{Date: day1, Rate: 1.44}, {Date: day2, Rate: 1.45}},
+		"USD": {{Date: day2, Rate: 1.35}},
+	}}
+	provider := NewTriangulatingProvider(base, []string{"SGD"})
+
+	rates, err := schemas.FetchHistoricalRates(provider, "USD", "EUR", day1, day2)
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	assert.Equal(t, day2, rates[0].Date)
+	assert.InDelta(t, 1.45/1.35, rates[0].Rate, 1e-12)
+}
+
+func TestTriangulatingProvider_BatchSharesPivotLegs(t *testing.T) {
+	base := &sgdOnlyProvider{stubRateProvider: stubRateProvider{rates: map[string]float64{"EUR": 1.45, "USD": 1.35, "JPY": 0.009}}}
+	provider := NewTriangulatingProvider(base, []string{"SGD"})
+
+	quotes, err := provider.LatestRates("USD", []string{"EUR", "JPY", "SGD"})
+	require.NoError(t, err)
+	require.Len(t, quotes, 3)
+	assert.InDelta(t, 0.009/1.35, quotes["JPY"].Rate, 1e-12)
+	assert.Equal(t, "SGD", quotes["EUR"].Via)
+	assert.InDelta(t, 1/1.35, quotes["SGD"].Rate, 1e-12)
+}
+
+func TestTriangulatingProvider_QuotesThePivotFromTheBaseLeg(t *testing.T) {
+	day := time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC)
+	base := &sgdOnlyProvider{
+		stubRateProvider: stubRateProvider{rates: map[string]float64{"MYR": 0.3}},
+		histories:        map[string][]schemas.HistoricalRate{"MYR": {{Date: day, Rate: 0.3}}},
+	}
+	provider := NewTriangulatingProvider(base, []string{"SGD", "EUR", "USD"})
+
+	quote, err := schemas.FetchLatestRate(provider, "MYR", "SGD")
+	require.NoError(t, err)
+	assert.InDelta(t, 1/0.3, quote.Rate, 1e-12)
+	assert.Equal(t, "SGD", quote.Via)
+	assert.Equal(t, "2026-02-20", quote.Date)
+
+	rates, err := schemas.FetchHistoricalRates(provider, "MYR", "SGD", day, day)
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	assert.InDelta(t, 1/0.3, rates[0].Rate, 1e-12)
+}
+
+func TestValidatePivotCurrencies(t *testing.T) {
+	provider := &stubRateProvider{rates: map[string]float64{"USD": 1.35, "EUR": 1.45}}
+	assert.NoError(t, ValidatePivotCurrencies(provider, []string{"SGD", "EUR", "USD"}))
+
+	err := ValidatePivotCurrencies(provider, []string{"SGD", "usd", "XYZ"})
+	assert.ErrorContains(t, err, "unknown pivot currencies usd, XYZ")
+
+	offline := &stubRateProvider{err: fmt.Errorf("offline")}
+	assert.NoError(t, ValidatePivotCurrencies(offline, []string{"EUR", "JPY"}))
+	assert.Error(t, ValidatePivotCurrencies(offline, []string{"XYZ"}))
+}
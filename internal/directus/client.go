@@ -0,0 +1,133 @@
+package directus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/httpclient"
+)
+
+const methodSearch = "SEARCH"
+
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    *httpclient.Client
+}
+
+func New(baseURL, token string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), Token: token}
+}
+
+func (c *Client) httpClient() *httpclient.Client {
+	if c.HTTP != nil {
+		return c.HTTP
+	}
+	return httpclient.Default
+}
+
+func itemsPath(collection string, id ...string) string {
+	p := "/items/" + url.PathEscape(collection)
+	if len(id) > 0 {
+		p += "/" + url.PathEscape(id[0])
+	}
+	return p
+}
+
+// Do sends a request to path with payload encoded as JSON and decodes the
+// "data" member of the response into out. Either may be nil.
+func (c *Client) Do(ctx context.Context, method, path string, payload, out any) error {
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", c.Token))
+
+	res, err := c.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return parseError(res, resBody)
+	}
+
+	if out == nil || len(resBody) == 0 {
+		return nil
+	}
+	response := struct {
+		Data any `json:"data"`
+	}{Data: out}
+	if err := json.Unmarshal(resBody, &response); err != nil {
+		return fmt.Errorf("decoding directus response from %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+func Create[T any](ctx context.Context, c *Client, collection string, item T) (T, error) {
+	var created T
+	err := c.Do(ctx, http.MethodPost, itemsPath(collection), item, &created)
+	return created, err
+}
+
+func CreateMany[T any](ctx context.Context, c *Client, collection string, items []T) error {
+	return c.Do(ctx, http.MethodPost, itemsPath(collection), items, nil)
+}
+
+func Get[T any](ctx context.Context, c *Client, collection, id string) (T, error) {
+	var item T
+	err := c.Do(ctx, http.MethodGet, itemsPath(collection, id), nil, &item)
+	return item, err
+}
+
+func Update[T any](ctx context.Context, c *Client, collection, id string, item T) (T, error) {
+	var updated T
+	err := c.Do(ctx, http.MethodPatch, itemsPath(collection, id), item, &updated)
+	return updated, err
+}
+
+// UpdateByQuery applies the same changes to every item matching query and
+// returns the items that were updated.
+func UpdateByQuery[T any](ctx context.Context, c *Client, collection string, query Query, data any) ([]T, error) {
+	var updated []T
+	err := c.Do(ctx, http.MethodPatch, itemsPath(collection), map[string]any{"query": query, "data": data}, &updated)
+	return updated, err
+}
+
+func Delete(ctx context.Context, c *Client, collection, id string) error {
+	return c.Do(ctx, http.MethodDelete, itemsPath(collection, id), nil, nil)
+}
+
+func Search[T any](ctx context.Context, c *Client, collection string, query Query) ([]T, error) {
+	var items []T
+	err := c.Do(ctx, methodSearch, itemsPath(collection), map[string]any{"query": query}, &items)
+	return items, err
+}
+
+func First[T any](ctx context.Context, c *Client, collection string, filter Filter) (*T, error) {
+	items, err := Search[T](ctx, c, collection, Query{Filter: filter, Limit: 1})
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
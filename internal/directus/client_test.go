@@ -0,0 +1,134 @@
+package directus
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	ID       string `json:"id,omitempty"`
+	Currency string `json:"currency"`
+}
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Auth   string
+	Body   map[string]any
+}
+
+func newTestClient(t *testing.T, status int, response string) (*Client, *recordedRequest) {
+	recorded := &recordedRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorded.Method = r.Method
+		recorded.Path = r.URL.Path
+		recorded.Auth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		if len(body) > 0 {
+			json.Unmarshal(body, &recorded.Body)
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+
+	c := New(server.URL+"/", "secret")
+	c.HTTP = httpclient.New(httpclient.Config{})
+	return c, recorded
+}
+
+func TestSearch(t *testing.T) {
+	c, recorded := newTestClient(t, http.StatusOK, `{"data":[{"id":"1","currency":"USD"},{"id":"2","currency":"EUR"}]}`)
+
+	items, err := Search[item](context.Background(), c, "things", Query{
+		Filter: And(Eq("chat_id", "42"), In("currency", []string{"USD", "EUR"})),
+		Sort:   []string{"date"},
+		Limit:  All,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []item{{"1", "USD"}, {"2", "EUR"}}, items)
+
+	assert.Equal(t, "SEARCH", recorded.Method)
+	assert.Equal(t, "/items/things", recorded.Path)
+	assert.Equal(t, "Bearer secret", recorded.Auth)
+	query := recorded.Body["query"].(map[string]any)
+	assert.Equal(t, float64(-1), query["limit"])
+	assert.Equal(t, []any{"date"}, query["sort"])
+	assert.Equal(t, map[string]any{"_and": []any{
+		map[string]any{"chat_id": map[string]any{"_eq": "42"}},
+		map[string]any{"currency": map[string]any{"_in": []any{"USD", "EUR"}}},
+	}}, query["filter"])
+}
+
+func TestFirst(t *testing.T) {
+	c, recorded := newTestClient(t, http.StatusOK, `{"data":[]}`)
+	got, err := First[item](context.Background(), c, "things", Eq("currency", "USD"))
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	assert.Equal(t, float64(1), recorded.Body["query"].(map[string]any)["limit"])
+}
+
+func TestCreateUpdateDelete(t *testing.T) {
+	c, recorded := newTestClient(t, http.StatusOK, `{"data":{"id":"abc","currency":"USD"}}`)
+	created, err := Create(context.Background(), c, "things", item{Currency: "USD"})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", created.ID)
+	assert.Equal(t, http.MethodPost, recorded.Method)
+	assert.Equal(t, "USD", recorded.Body["currency"])
+
+	_, err = Update(context.Background(), c, "things", "a/b", item{Currency: "EUR"})
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPatch, recorded.Method)
+	assert.Equal(t, "/items/things/a/b", recorded.Path)
+
+	c, recorded = newTestClient(t, http.StatusNoContent, "")
+	require.NoError(t, Delete(context.Background(), c, "things", "abc"))
+	assert.Equal(t, http.MethodDelete, recorded.Method)
+	assert.Equal(t, "/items/things/abc", recorded.Path)
+}
+
+func TestErrors(t *testing.T) {
+	c, _ := newTestClient(t, http.StatusForbidden, `{"errors":[{"message":"You don't have permission to access this.","extensions":{"code":"FORBIDDEN"}}]}`)
+	_, err := Get[item](context.Background(), c, "things", "missing")
+	require.Error(t, err)
+	assert.True(t, IsNotFound(err))
+	e, ok := AsError(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusForbidden, e.StatusCode)
+	assert.Equal(t, CodeForbidden, e.Code())
+	assert.Contains(t, err.Error(), "GET /items/things/missing returned 403: FORBIDDEN: You don't have permission")
+
+	c, _ = newTestClient(t, http.StatusBadRequest, `{"errors":[{"message":"Value for field \"chat_id\" has to be unique.","extensions":{"code":"RECORD_NOT_UNIQUE"}}]}`)
+	_, err = Create(context.Background(), c, "things", item{})
+	assert.True(t, IsNotUnique(err))
+	assert.False(t, IsNotFound(err))
+
+	c, _ = newTestClient(t, http.StatusBadGateway, "upstream unavailable")
+	err = CreateMany(context.Background(), c, "things", []item{{}})
+	e, ok = AsError(err)
+	require.True(t, ok)
+	assert.Equal(t, "upstream unavailable", e.Body)
+	assert.Contains(t, err.Error(), "upstream unavailable")
+}
+
+func TestFilterBuilders(t *testing.T) {
+	encoded, err := json.Marshal(Or(
+		Between("date", "2025-01-01", "2025-01-31"),
+		Gte("rate", 1.3),
+		IsNull("interval"),
+	))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"_or":[
+		{"date":{"_between":["2025-01-01","2025-01-31"]}},
+		{"rate":{"_gte":1.3}},
+		{"interval":{"_null":true}}
+	]}`, string(encoded))
+}
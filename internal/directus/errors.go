@@ -0,0 +1,105 @@
+package directus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	CodeForbidden       = "FORBIDDEN"
+	CodeInvalidPayload  = "INVALID_PAYLOAD"
+	CodeInvalidQuery    = "INVALID_QUERY"
+	CodeRecordNotUnique = "RECORD_NOT_UNIQUE"
+	CodeRouteNotFound   = "ROUTE_NOT_FOUND"
+	CodeTokenExpired    = "TOKEN_EXPIRED"
+)
+
+type ErrorDetail struct {
+	Message    string `json:"message"`
+	Extensions struct {
+		Code string `json:"code"`
+	} `json:"extensions"`
+}
+
+type Error struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Details    []ErrorDetail
+	Body       string
+}
+
+func (e *Error) Error() string {
+	var messages []string
+	for _, d := range e.Details {
+		if d.Extensions.Code != "" {
+			messages = append(messages, fmt.Sprintf("%s: %s", d.Extensions.Code, d.Message))
+		} else {
+			messages = append(messages, d.Message)
+		}
+	}
+	if len(messages) == 0 && e.Body != "" {
+		messages = append(messages, e.Body)
+	}
+	return fmt.Sprintf("directus %s %s returned %d: %s", e.Method, e.Path, e.StatusCode, strings.Join(messages, "; "))
+}
+
+func (e *Error) Code() string {
+	if len(e.Details) == 0 {
+		return ""
+	}
+	return e.Details[0].Extensions.Code
+}
+
+func (e *Error) HasCode(code string) bool {
+	for _, d := range e.Details {
+		if d.Extensions.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func parseError(res *http.Response, body []byte) *Error {
+	e := &Error{
+		StatusCode: res.StatusCode,
+		Method:     res.Request.Method,
+		Path:       res.Request.URL.Path,
+	}
+	var payload struct {
+		Errors []ErrorDetail `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil && len(payload.Errors) > 0 {
+		e.Details = payload.Errors
+	} else {
+		e.Body = strings.TrimSpace(string(body))
+	}
+	return e
+}
+
+func AsError(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// IsNotFound reports whether err is Directus saying the item does not exist.
+// Directus answers 403 rather than 404 for missing items unless the token can
+// read the whole collection, so both are treated as not found.
+func IsNotFound(err error) bool {
+	e, ok := AsError(err)
+	if !ok {
+		return false
+	}
+	return e.StatusCode == http.StatusNotFound || (e.StatusCode == http.StatusForbidden && e.HasCode(CodeForbidden))
+}
+
+func IsNotUnique(err error) bool {
+	e, ok := AsError(err)
+	return ok && e.HasCode(CodeRecordNotUnique)
+}
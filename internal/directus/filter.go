@@ -0,0 +1,46 @@
+package directus
+
+type Filter map[string]any
+
+func op(field, operator string, value any) Filter {
+	return Filter{field: map[string]any{operator: value}}
+}
+
+func Eq(field string, value any) Filter  { return op(field, "_eq", value) }
+func Neq(field string, value any) Filter { return op(field, "_neq", value) }
+func Gt(field string, value any) Filter  { return op(field, "_gt", value) }
+func Gte(field string, value any) Filter { return op(field, "_gte", value) }
+func Lt(field string, value any) Filter  { return op(field, "_lt", value) }
+func Lte(field string, value any) Filter { return op(field, "_lte", value) }
+
+func In[T any](field string, values []T) Filter {
+	return op(field, "_in", values)
+}
+
+func Between(field string, from, to any) Filter {
+	return op(field, "_between", []any{from, to})
+}
+
+func IsNull(field string) Filter {
+	return op(field, "_null", true)
+}
+
+func And(filters ...Filter) Filter {
+	return Filter{"_and": filters}
+}
+
+func Or(filters ...Filter) Filter {
+	return Filter{"_or": filters}
+}
+
+type Query struct {
+	Filter Filter   `json:"filter,omitempty"`
+	Fields []string `json:"fields,omitempty"`
+	Sort   []string `json:"sort,omitempty"`
+	Limit  int      `json:"limit,omitempty"`
+	Offset int      `json:"offset,omitempty"`
+}
+
+// All is the Limit that asks Directus for every matching item instead of its
+// default page of 100.
+const All = -1
@@ -0,0 +1,144 @@
+package directus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+const (
+	EventInit   = "init"
+	EventCreate = "create"
+	EventUpdate = "update"
+	EventDelete = "delete"
+)
+
+// realtimeReadLimit caps a single websocket message. The init event carries
+// every item in the collection, so it is far larger than the library default.
+const realtimeReadLimit = 64 << 20
+
+// An Event is a change to a subscribed collection. Data holds the full items
+// for init, create and update events, and the deleted keys for delete events.
+type Event struct {
+	Collection string
+	Event      string
+	Data       json.RawMessage
+}
+
+type realtimeMessage struct {
+	Type   string          `json:"type"`
+	Event  string          `json:"event,omitempty"`
+	UID    string          `json:"uid,omitempty"`
+	Status string          `json:"status,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+	Error  *ErrorDetail    `json:"error,omitempty"`
+}
+
+func (c *Client) realtimeURL() string {
+	u := c.BaseURL
+	switch {
+	case strings.HasPrefix(u, "https://"):
+		u = "wss://" + strings.TrimPrefix(u, "https://")
+	case strings.HasPrefix(u, "http://"):
+		u = "ws://" + strings.TrimPrefix(u, "http://")
+	}
+	return u + "/websocket"
+}
+
+// Subscribe opens a realtime connection, subscribes to every field of each
+// collection and calls handle for each event until ctx is cancelled, the
+// connection drops or handle returns an error. The first event for each
+// collection is an init event with its current contents.
+func (c *Client) Subscribe(ctx context.Context, collections []string, handle func(Event) error) error {
+	conn, _, err := websocket.Dial(ctx, c.realtimeURL(), nil)
+	if err != nil {
+		return fmt.Errorf("connecting to directus realtime: %w", err)
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(realtimeReadLimit)
+
+	if err := wsjson.Write(ctx, conn, map[string]string{"type": "auth", "access_token": c.Token}); err != nil {
+		return err
+	}
+	var reply realtimeMessage
+	if err := wsjson.Read(ctx, conn, &reply); err != nil {
+		return err
+	}
+	if reply.Type != "auth" || reply.Status != "ok" {
+		return fmt.Errorf("directus realtime authentication failed: %s", reply.errorMessage())
+	}
+
+	for _, collection := range collections {
+		if err := wsjson.Write(ctx, conn, map[string]any{
+			"type":       "subscribe",
+			"collection": collection,
+			"uid":        collection,
+			"query":      Query{Fields: []string{"*"}, Limit: All},
+		}); err != nil {
+			return err
+		}
+	}
+
+	for {
+		var msg realtimeMessage
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			return err
+		}
+		switch msg.Type {
+		case "ping":
+			if err := wsjson.Write(ctx, conn, map[string]string{"type": "pong"}); err != nil {
+				return err
+			}
+		case "subscription":
+			if msg.Status == "error" {
+				return fmt.Errorf("directus realtime subscription %s failed: %s", msg.UID, msg.errorMessage())
+			}
+			if err := handle(Event{Collection: msg.UID, Event: msg.Event, Data: msg.Data}); err != nil {
+				conn.Close(websocket.StatusNormalClosure, "")
+				return err
+			}
+		case "auth":
+			if msg.Status == "error" {
+				return fmt.Errorf("directus realtime authentication expired: %s", msg.errorMessage())
+			}
+		}
+	}
+}
+
+func (m realtimeMessage) errorMessage() string {
+	if m.Error == nil {
+		return "no error details"
+	}
+	if m.Error.Extensions.Code != "" {
+		return m.Error.Extensions.Code + ": " + m.Error.Message
+	}
+	return m.Error.Message
+}
+
+// DeletedKeys decodes the keys of a delete event. Directus sends them as
+// strings or numbers depending on the primary key type.
+func (e Event) DeletedKeys() ([]string, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(e.Data, &raw); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(raw))
+	for _, r := range raw {
+		var key string
+		if err := json.Unmarshal(r, &key); err == nil {
+			keys = append(keys, key)
+			continue
+		}
+		var number json.Number
+		if err := json.Unmarshal(r, &number); err != nil {
+			return nil, errors.New("unexpected key in delete event: " + string(r))
+		}
+		keys = append(keys, number.String())
+	}
+	return keys, nil
+}
@@ -0,0 +1,97 @@
+package directus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribe(t *testing.T) {
+	var subscribed []map[string]any
+	var pong map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/websocket", r.URL.Path)
+		conn, err := websocket.Accept(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.CloseNow()
+		ctx := r.Context()
+
+		var auth map[string]string
+		assert.NoError(t, wsjson.Read(ctx, conn, &auth))
+		assert.Equal(t, map[string]string{"type": "auth", "access_token": "secret"}, auth)
+		wsjson.Write(ctx, conn, map[string]string{"type": "auth", "status": "ok"})
+
+		for range 2 {
+			var msg map[string]any
+			assert.NoError(t, wsjson.Read(ctx, conn, &msg))
+			subscribed = append(subscribed, msg)
+		}
+		wsjson.Write(ctx, conn, map[string]any{"type": "ping"})
+		assert.NoError(t, wsjson.Read(ctx, conn, &pong))
+
+		wsjson.Write(ctx, conn, map[string]any{"type": "subscription", "event": "init", "uid": "things", "data": []item{{"1", "USD"}}})
+		wsjson.Write(ctx, conn, map[string]any{"type": "subscription", "event": "delete", "uid": "things", "data": []any{"1", 2}})
+		conn.Read(ctx)
+	}))
+	t.Cleanup(server.Close)
+
+	var events []Event
+	err := New(server.URL, "secret").Subscribe(context.Background(), []string{"things", "others"}, func(e Event) error {
+		events = append(events, e)
+		if len(events) == 2 {
+			return errors.New("done")
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "done")
+
+	require.Len(t, subscribed, 2)
+	assert.Equal(t, "things", subscribed[0]["collection"])
+	assert.Equal(t, "things", subscribed[0]["uid"])
+	assert.Equal(t, map[string]any{"fields": []any{"*"}, "limit": float64(-1)}, subscribed[0]["query"])
+	assert.Equal(t, "others", subscribed[1]["collection"])
+	assert.Equal(t, "pong", pong["type"])
+
+	require.Len(t, events, 2)
+	assert.Equal(t, EventInit, events[0].Event)
+	var items []item
+	require.NoError(t, json.Unmarshal(events[0].Data, &items))
+	assert.Equal(t, []item{{"1", "USD"}}, items)
+
+	keys, err := events[1].DeletedKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, keys)
+}
+
+func TestSubscribe_AuthFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.CloseNow()
+		var auth map[string]string
+		wsjson.Read(r.Context(), conn, &auth)
+		wsjson.Write(r.Context(), conn, map[string]any{
+			"type": "auth", "status": "error",
+			"error": map[string]any{"message": "Invalid user credentials.", "extensions": map[string]string{"code": "INVALID_CREDENTIALS"}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := New(server.URL, "wrong").Subscribe(ctx, []string{"things"}, func(Event) error { return nil })
+	assert.ErrorContains(t, err, "INVALID_CREDENTIALS")
+}
@@ -0,0 +1,42 @@
+package directus
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+type Collection struct {
+	Collection string         `json:"collection"`
+	Meta       map[string]any `json:"meta,omitempty"`
+	Schema     map[string]any `json:"schema"`
+	Fields     []Field        `json:"fields,omitempty"`
+}
+
+type Field struct {
+	Collection string         `json:"collection,omitempty"`
+	Field      string         `json:"field"`
+	Type       string         `json:"type"`
+	Meta       map[string]any `json:"meta,omitempty"`
+	Schema     map[string]any `json:"schema,omitempty"`
+}
+
+func (c *Client) Collections(ctx context.Context) ([]Collection, error) {
+	var collections []Collection
+	err := c.Do(ctx, http.MethodGet, "/collections", nil, &collections)
+	return collections, err
+}
+
+func (c *Client) CreateCollection(ctx context.Context, collection Collection) error {
+	return c.Do(ctx, http.MethodPost, "/collections", collection, nil)
+}
+
+func (c *Client) Fields(ctx context.Context, collection string) ([]Field, error) {
+	var fields []Field
+	err := c.Do(ctx, http.MethodGet, "/fields/"+url.PathEscape(collection), nil, &fields)
+	return fields, err
+}
+
+func (c *Client) CreateField(ctx context.Context, collection string, field Field) error {
+	return c.Do(ctx, http.MethodPost, "/fields/"+url.PathEscape(collection), field, nil)
+}
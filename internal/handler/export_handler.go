@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/core"
+	"github.com/Jason-CKY/telegram-notifybot/internal/httpclient"
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/store"
+	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
+	log "github.com/sirupsen/logrus"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const maxImportFileSize = 1 << 20
+
+// fileURLGetter is implemented by *tgbotapi.BotAPI. Importing needs it to
+// download the replied-to document.
+type fileURLGetter interface {
+	GetFileDirectURL(fileID string) (string, error)
+}
+
+func HandleFXExportCommand(update *tgbotapi.Update, bot core.MessageSender) {
+	format := core.ExportFormatJSON
+	if args := strings.Fields(update.Message.CommandArguments()); len(args) > 0 {
+		format = strings.ToLower(args[0])
+	}
+	if format != core.ExportFormatJSON && format != core.ExportFormatCSV {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Usage: /fx_export [json|csv]")
+		bot.Send(msg)
+		return
+	}
+
+	base, ok := getChatBaseCurrency(update, bot)
+	if !ok {
+		return
+	}
+	subscriptions, err := store.Subscriptions.ListByChat(context.Background(), update.Message.Chat.ID)
+	if err != nil {
+		log.Error(err)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+			fmt.Sprintf("Error fetching subscriptions: %v", err))
+		bot.Send(msg)
+		return
+	}
+
+	export := core.NewSubscriptionExport(schemas.ChatSettings{ChatId: update.Message.Chat.ID, BaseCurrency: base}, subscriptions)
+	data, err := export.Encode(format)
+	if err != nil {
+		log.Error(err)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+			fmt.Sprintf("Error exporting subscriptions: %v", err))
+		bot.Send(msg)
+		return
+	}
+
+	document := tgbotapi.NewDocument(update.Message.Chat.ID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("notifybot-alerts-%s.%s", time.Now().Format("2006-01-02"), format),
+		Bytes: data,
+	})
+	document.Caption = fmt.Sprintf("📦 Exported %d alerts and home currency %s.\n\nReply to this file with /fx_import in another chat to copy them there.", len(export.Alerts), base)
+	bot.Send(document)
+}
+
+func HandleFXImportCommand(update *tgbotapi.Update, bot core.MessageSender) {
+	reply := update.Message.ReplyToMessage
+	if reply == nil || reply.Document == nil {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+			"Reply to a JSON or CSV file created by /fx_export with /fx_import to import its alerts into this chat.")
+		bot.Send(msg)
+		return
+	}
+
+	data, err := downloadDocument(bot, reply.Document)
+	if err != nil {
+		log.Error(err)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+			fmt.Sprintf("Error downloading file: %v", err))
+		bot.Send(msg)
+		return
+	}
+
+	format := core.DetectExportFormat(reply.Document.FileName, data)
+	base, rows, err := core.ParseSubscriptionImport(format, data)
+	if err != nil {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("Could not import %s: %v", reply.Document.FileName, err))
+		bot.Send(msg)
+		return
+	}
+
+	var lines []string
+	if base != "" {
+		base = strings.ToUpper(base)
+		if !utils.IsCurrencySupported(base) {
+			lines = append(lines, fmt.Sprintf("⚠️ Home currency: unsupported currency %q, keeping the current one", base))
+		} else if _, err := store.SetChatBaseCurrency(context.Background(), update.Message.Chat.ID, base); err != nil {
+			log.Error(err)
+			lines = append(lines, fmt.Sprintf("⚠️ Home currency: %v", err))
+		} else {
+			lines = append(lines, fmt.Sprintf("Home currency set to %s", base))
+		}
+	}
+
+	imported, existing := 0, 0
+	rates := make(map[string]float64)
+	for _, row := range rows {
+		if row.Err != nil {
+			lines = append(lines, fmt.Sprintf("⚠️ Row %d: %v", row.Row, row.Err))
+			continue
+		}
+		sub := &schemas.CurrencySubscription{
+			ChatID:         update.Message.Chat.ID,
+			BaseCurrency:   row.Alert.BaseCurrency,
+			Currency:       row.Alert.Currency,
+			ThresholdAbove: row.Alert.ThresholdAbove,
+			ThresholdBelow: row.Alert.ThresholdBelow,
+			Interval:       row.Alert.Interval,
+
+			ThresholdAbovePercent: row.Alert.ThresholdAbovePercent,
+			ThresholdBelowPercent: row.Alert.ThresholdBelowPercent,
+			IntervalPercent:       row.Alert.IntervalPercent,
+			ReferenceRate:         row.Alert.ReferenceRate,
+
+			Repeat:            row.Alert.Repeat,
+			Hysteresis:        row.Alert.Hysteresis,
+			HysteresisPercent: row.Alert.HysteresisPercent,
+
+			Ladder:          row.Alert.Ladder,
+			LadderDirection: row.Alert.LadderDirection,
+
+			Indicator: row.Alert.Indicator,
+		}
+		rate, ok := rates[sub.Pair()]
+		if !ok {
+			rate, _, _ = core.GetCurrentRate(sub.Base(), sub.Currency)
+			rates[sub.Pair()] = rate
+		}
+		sub.LastNotifiedRate = rate
+		if sub.HasInterval() && rate == 0 {
+			lines = append(lines, fmt.Sprintf("⚠️ Row %d: the current %s rate to count the interval from is unavailable", row.Row, sub.Pair()))
+			continue
+		}
+		if sub.UsesPercentage() && sub.ReferenceRate == 0 {
+			if rate == 0 {
+				lines = append(lines, fmt.Sprintf("⚠️ Row %d: no reference_rate and the current %s rate is unavailable", row.Row, sub.Pair()))
+				continue
+			}
+			sub.ReferenceRate = rate
+		}
+
+		_, existed, err := store.AddSubscription(context.Background(), sub)
+		switch {
+		case err != nil:
+			log.Error(err)
+			lines = append(lines, fmt.Sprintf("⚠️ Row %d: %v", row.Row, err))
+		case existed:
+			existing++
+		default:
+			imported++
+		}
+	}
+
+	summary := fmt.Sprintf("✅ Imported %d alerts", imported)
+	if existing > 0 {
+		summary += fmt.Sprintf(", %d already existed", existing)
+	}
+	summary += "."
+	if len(lines) > 0 {
+		summary += "\n\n" + strings.Join(lines, "\n")
+	}
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, summary)
+	bot.Send(msg)
+}
+
+func downloadDocument(bot core.MessageSender, document *tgbotapi.Document) ([]byte, error) {
+	if document.FileSize > maxImportFileSize {
+		return nil, fmt.Errorf("file is larger than %d KB", maxImportFileSize>>10)
+	}
+	getter, ok := bot.(fileURLGetter)
+	if !ok {
+		return nil, errors.New("file downloads are not supported")
+	}
+	fileURL, err := getter.GetFileDirectURL(document.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := httpclient.Default.Do(req)
+	if err != nil {
+		// The file URL embeds the bot token, so drop it from the error.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("telegram returned %s", res.Status)
+	}
+	return io.ReadAll(io.LimitReader(res.Body, maxImportFileSize))
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/store"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fileSender serves every requested file from files, keyed by file ID.
+type fileSender struct {
+	recordingSender
+	server *httptest.Server
+}
+
+func newFileSender(t *testing.T, files map[string][]byte) *fileSender {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path[1:]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return &fileSender{server: server}
+}
+
+func (s *fileSender) GetFileDirectURL(fileID string) (string, error) {
+	return s.server.URL + "/" + fileID, nil
+}
+
+func replyWithDocument(update *tgbotapi.Update, fileID, fileName string) *tgbotapi.Update {
+	update.Message.ReplyToMessage = &tgbotapi.Message{Document: &tgbotapi.Document{FileID: fileID, FileName: fileName}}
+	return update
+}
+
+func TestHandleFXExportAndImportCommands(t *testing.T) {
+	recorder := setupHandlerTest(t)
+	ctx := context.Background()
+
+	HandleSettingsCommand(commandUpdate(1, "/settings base MYR"), recorder)
+	HandleFXSubscribeCommand(commandUpdate(1, "/fx_subscribe USD SGD -above 1.40 -below 1.30"), recorder)
+	HandleFXIntervalCommand(commandUpdate(1, "/fx_interval EUR 0.05"), recorder)
+
+	files := make(map[string][]byte)
+	for _, format := range []string{"json", "csv"} {
+		HandleFXExportCommand(commandUpdate(1, "/fx_export "+format), recorder)
+		document, ok := recorder.sent[len(recorder.sent)-1].(tgbotapi.DocumentConfig)
+		require.True(t, ok)
+		assert.Contains(t, document.Caption, "Exported 3 alerts and home currency MYR")
+		files[format] = document.File.(tgbotapi.FileBytes).Bytes
+	}
+	files["broken"] = []byte("kind,base_currency,currency,threshold_above,threshold_below,interval\nalert,SGD,XYZ,1,,\nalert,SGD,USD,1.5,,\nalert,SGD,JPY,,,0.001\n")
+	sender := newFileSender(t, files)
+
+	HandleFXImportCommand(replyWithDocument(commandUpdate(2, "/fx_import"), "json", "alerts.json"), sender)
+	assert.Contains(t, sender.lastText(), "Imported 3 alerts")
+	assert.Contains(t, sender.lastText(), "Home currency set to MYR")
+	HandleFXImportCommand(replyWithDocument(commandUpdate(2, "/fx_import"), "csv", "alerts.csv"), sender)
+	assert.Contains(t, sender.lastText(), "Imported 0 alerts, 3 already existed")
+
+	subscriptions, err := store.Subscriptions.ListByChat(ctx, 2)
+	require.NoError(t, err)
+	assert.Len(t, subscriptions, 3)
+	base, err := store.GetChatBaseCurrency(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "MYR", base)
+
+	HandleFXImportCommand(replyWithDocument(commandUpdate(3, "/fx_import"), "broken", "broken.csv"), sender)
+	assert.Contains(t, sender.lastText(), "Imported 1 alerts")
+	assert.Contains(t, sender.lastText(), `Row 2: unsupported currency "XYZ"`)
+	assert.Contains(t, sender.lastText(), "Row 4: the current JPY/SGD rate to count the interval from is unavailable")
+
+	HandleFXImportCommand(commandUpdate(3, "/fx_import"), sender)
+	assert.Contains(t, sender.lastText(), "Reply to a JSON or CSV file")
+}
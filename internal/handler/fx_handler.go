@@ -1,37 +1,40 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Jason-CKY/telegram-notifybot/internal/core"
+	"github.com/Jason-CKY/telegram-notifybot/internal/core/indicators"
 	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/store"
 	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
 	log "github.com/sirupsen/logrus"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func HandleFXCommand(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
+func HandleFXCommand(update *tgbotapi.Update, bot core.MessageSender) {
 	args := strings.Fields(update.Message.CommandArguments())
 	if len(args) == 0 {
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"Usage: /fx <currency>\nExample: /fx USD")
+			"Usage: /fx <currency> [quote currency]\nExample: /fx USD\nExample: /fx EUR USD")
 		bot.Send(msg)
 		return
 	}
 
-	currency := strings.ToUpper(args[0])
-	if !utils.IsCurrencySupported(currency) {
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			fmt.Sprintf("Unsupported currency: %s\n\nSupported currencies: %s",
-				currency, strings.Join(utils.SupportedCurrencies, ", ")))
-		bot.Send(msg)
+	base, currency, _, ok := resolvePair(update, bot, args)
+	if !ok {
 		return
 	}
 
-	rate, response, err := core.GetCurrentRate(currency)
+	rate, quote, err := core.GetCurrentRate(base, currency)
 	if err != nil {
 		log.Error(err)
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
@@ -41,36 +44,32 @@ func HandleFXCommand(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
 	}
 
 	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		core.FormatCurrentRateMessage(currency, rate, response))
+		core.FormatCurrentRateMessage(base, currency, rate, quote))
 	bot.Send(msg)
 }
 
-func HandleFXChartCommand(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
+func HandleFXChartCommand(update *tgbotapi.Update, bot core.MessageSender) {
 	args := strings.Fields(update.Message.CommandArguments())
 	if len(args) == 0 {
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"Usage: /fx_chart <currency> [months]\nExample: /fx_chart USD 6")
+			"Usage: /fx_chart <currency> [quote currency] [months]\nExample: /fx_chart USD 6\nExample: /fx_chart EUR USD 6")
 		bot.Send(msg)
 		return
 	}
 
-	currency := strings.ToUpper(args[0])
-	if !utils.IsCurrencySupported(currency) {
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			fmt.Sprintf("Unsupported currency: %s\n\nSupported currencies: %s",
-				currency, strings.Join(utils.SupportedCurrencies, ", ")))
-		bot.Send(msg)
+	base, currency, rest, ok := resolvePair(update, bot, args)
+	if !ok {
 		return
 	}
 
 	months := 12
-	if len(args) > 1 {
-		if m, err := strconv.Atoi(args[1]); err == nil && m > 0 {
+	if len(rest) > 0 {
+		if m, err := strconv.Atoi(rest[0]); err == nil && m > 0 {
 			months = m
 		}
 	}
 
-	rates, err := core.GetHistoricalRates(currency, months)
+	rates, err := core.GetHistoricalRates(base, currency, months)
 	if err != nil {
 		log.Error(err)
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
@@ -79,7 +78,7 @@ func HandleFXChartCommand(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
 		return
 	}
 
-	chartBuf, err := core.GenerateExchangeRateChart(rates, currency)
+	chartBuf, err := core.GenerateExchangeRateChart(rates, base, currency)
 	if err != nil {
 		log.Error(err)
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
@@ -93,176 +92,448 @@ func HandleFXChartCommand(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
 		Bytes: *chartBuf,
 	}
 	photoConfig := tgbotapi.NewPhoto(update.Message.Chat.ID, photoFileBytes)
-	photoConfig.Caption = fmt.Sprintf("📊 %s/SGD Exchange Rate (%d months)", currency, months)
+	photoConfig.Caption = fmt.Sprintf("📊 %s/%s Exchange Rate (%d months)", currency, base, months)
 	bot.Send(photoConfig)
 }
 
-func HandleFXSubscribeCommand(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
+func HandleFXSubscribeCommand(update *tgbotapi.Update, bot core.MessageSender) {
 	args := update.Message.CommandArguments()
 
 	if args == "" {
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"Usage: /fx_subscribe <currency> -above <rate> OR -below <rate>\n\n"+
+			"Usage: /fx_subscribe <currency> [quote currency] -above <rate|percent> OR -below <rate|percent>\n\n"+
 				"Examples:\n"+
 				"/fx_subscribe USD -above 1.40\n"+
-				"/fx_subscribe EUR -below 1.45")
+				"/fx_subscribe EUR -below 1.45\n"+
+				"/fx_subscribe EUR USD -above 1.10\n"+
+				"/fx_subscribe USD -above +2% -below -3%\n"+
+				"/fx_subscribe USD -above 1.40 -repeat 0.5%\n\n"+
+				"Percentages are relative to the current rate. -repeat keeps the alert after it fires and re-arms it once the rate moves back past the threshold by the given band (default 0.5%).")
 		bot.Send(msg)
 		return
 	}
 
-	currency := ""
-	var thresholdAbove, thresholdBelow *float64
+	base, currency, parts, ok := resolvePair(update, bot, strings.Fields(args))
+	if !ok {
+		return
+	}
 
-	parts := strings.Fields(args)
+	var above, below *schemas.CurrencySubscription
+	repeat := false
+	band, bandPercent := schemas.DefaultHysteresisPercent, true
 	for i, part := range parts {
-		if strings.ToUpper(part) == "-ABOVE" && i+1 < len(parts) {
-			currency = strings.ToUpper(parts[0])
-			if val, err := strconv.ParseFloat(parts[i+1], 64); err == nil {
-				thresholdAbove = &val
+		if strings.ToUpper(part) == "-REPEAT" {
+			repeat = true
+			if i+1 < len(parts) {
+				if value, percent, err := parseLevel(parts[i+1]); err == nil && value > 0 {
+					band, bandPercent = value, percent
+				}
 			}
-		} else if strings.ToUpper(part) == "-BELOW" && i+1 < len(parts) {
-			currency = strings.ToUpper(parts[0])
-			if val, err := strconv.ParseFloat(parts[i+1], 64); err == nil {
-				thresholdBelow = &val
+			continue
+		}
+		if i+1 >= len(parts) {
+			continue
+		}
+		value, percent, err := parseLevel(parts[i+1])
+		if err != nil {
+			continue
+		}
+		alert := &schemas.CurrencySubscription{ChatID: update.Message.Chat.ID, BaseCurrency: base, Currency: currency}
+		switch strings.ToUpper(part) {
+		case "-ABOVE":
+			if !percent {
+				alert.ThresholdAbove = &value
+			} else if value > 0 {
+				alert.ThresholdAbovePercent = &value
+			} else {
+				continue
+			}
+			above = alert
+		case "-BELOW":
+			if !percent {
+				alert.ThresholdBelow = &value
+			} else if value != 0 {
+				fall := -math.Abs(value)
+				alert.ThresholdBelowPercent = &fall
+			} else {
+				continue
+			}
+			below = alert
+		}
+	}
+
+	if above == nil && below == nil {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+			"Please specify -above or -below with a rate or percentage.\nExample: /fx_subscribe USD -above 1.40\nExample: /fx_subscribe USD -above +2%")
+		bot.Send(msg)
+		return
+	}
+
+	var alerts []*schemas.CurrencySubscription
+	if above != nil {
+		alerts = append(alerts, above)
+	}
+	if below != nil {
+		alerts = append(alerts, below)
+	}
+	note := "Note: Threshold alerts fire once and are then removed."
+	if repeat {
+		for _, alert := range alerts {
+			alert.Repeat = true
+			if bandPercent {
+				alert.HysteresisPercent = &band
+			} else {
+				alert.Hysteresis = &band
 			}
 		}
+		note = "Note: Repeating alerts stay active and fire again once the rate has moved back past the band."
 	}
 
-	if currency == "" && len(parts) > 0 {
-		currency = strings.ToUpper(parts[0])
+	lines, ok := addAlerts(update, bot, alerts)
+	if !ok {
+		return
 	}
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+		strings.Join(lines, "\n")+"\n\n"+note+" Use /fx_list to see all your alerts.")
+	bot.Send(msg)
+}
+
+// parseLevel reads a rate such as "1.40" or a percentage such as "+2%".
+func parseLevel(s string) (value float64, percent bool, err error) {
+	number := strings.TrimSuffix(s, "%")
+	value, err = strconv.ParseFloat(number, 64)
+	return value, number != s, err
+}
 
-	if currency == "" {
+// addAlerts stores each alert with the current rate as its starting point and
+// returns one confirmation line per alert. Percentage, interval and ladder
+// alerts are refused when the current rate cannot be fetched, since they
+// would start from nothing. Ladder rungs the rate has already passed are
+// dropped.
+func addAlerts(update *tgbotapi.Update, bot core.MessageSender, alerts []*schemas.CurrencySubscription) ([]string, bool) {
+	var currentRate float64
+	var rateErr error
+	if len(alerts) > 0 {
+		currentRate, _, rateErr = core.GetCurrentRate(alerts[0].Base(), alerts[0].Currency)
+	}
+
+	lines := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		if alert.NeedsCurrentRate() && rateErr != nil {
+			log.Error(rateErr)
+			msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				fmt.Sprintf("Error fetching the current %s rate for this alert to start from: %v", alert.Pair(), rateErr))
+			bot.Send(msg)
+			return nil, false
+		}
+		if alert.UsesPercentage() {
+			alert.ReferenceRate = currentRate
+		}
+		var skipped []float64
+		if len(alert.Ladder) > 0 {
+			skipped = alert.DropCrossedRungs(currentRate)
+			if len(alert.Ladder) == 0 {
+				msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+					fmt.Sprintf("%s is already at %.4f, past every rung of the ladder.", alert.Pair(), currentRate))
+				bot.Send(msg)
+				return nil, false
+			}
+		}
+		alert.LastNotifiedRate = currentRate
+		sub, existed, err := store.AddSubscription(context.Background(), alert)
+		if err != nil {
+			log.Error(err)
+			msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				fmt.Sprintf("Error creating subscription: %v", err))
+			bot.Send(msg)
+			return nil, false
+		}
+		if existed {
+			lines = append(lines, fmt.Sprintf("ℹ️ Alert %s already exists: %s", sub.ShortID(), sub.Describe()))
+		} else {
+			lines = append(lines, fmt.Sprintf("✅ Alert %s added: %s", sub.ShortID(), sub.Describe()))
+		}
+		if len(skipped) > 0 {
+			lines = append(lines, fmt.Sprintf("ℹ️ Skipped %d rungs the rate of %.4f has already passed", len(skipped), currentRate))
+		}
+	}
+	return lines, true
+}
+
+func HandleFXIntervalCommand(update *tgbotapi.Update, bot core.MessageSender) {
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) < 2 {
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"Please specify a currency.\nExample: /fx_subscribe USD -above 1.40")
+			"Usage: /fx_interval <currency> [quote currency] <interval|percent>\n\n"+
+				"Example: /fx_interval USD 0.05\n"+
+				"This will notify you every time the rate changes by 0.05 units of your home currency or more.\n\n"+
+				"Example: /fx_interval USD 1%\n"+
+				"This will notify you every time the rate moves 1% from the last alerted rate.")
 		bot.Send(msg)
 		return
 	}
 
-	if !utils.IsCurrencySupported(currency) {
+	base, currency, rest, ok := resolvePair(update, bot, args)
+	if !ok {
+		return
+	}
+
+	var interval float64
+	var percent bool
+	if len(rest) > 0 {
+		interval, percent, _ = parseLevel(rest[0])
+	}
+	if interval <= 0 {
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			fmt.Sprintf("Unsupported currency: %s\n\nSupported currencies: %s",
-				currency, strings.Join(utils.SupportedCurrencies, ", ")))
+			"Please provide a valid positive number or percentage for the interval.\nExample: /fx_interval USD 0.05\nExample: /fx_interval USD 1%")
 		bot.Send(msg)
 		return
 	}
 
-	if thresholdAbove == nil && thresholdBelow == nil {
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"Please specify -above or -below with a rate.\nExample: /fx_subscribe USD -above 1.40")
+	alert := &schemas.CurrencySubscription{ChatID: update.Message.Chat.ID, BaseCurrency: base, Currency: currency}
+	step := fmt.Sprintf("%.4f %s", interval, base)
+	if percent {
+		alert.IntervalPercent = &interval
+		step = fmt.Sprintf("%.2f%%", interval)
+	} else {
+		alert.Interval = &interval
+	}
+	lines, ok := addAlerts(update, bot, []*schemas.CurrencySubscription{alert})
+	if !ok {
+		return
+	}
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+		lines[0]+fmt.Sprintf("\n\nYou will be notified every time the rate changes by %s or more.", step))
+	bot.Send(msg)
+}
+
+const maxLadderRungs = 50
+
+func HandleFXLadderCommand(update *tgbotapi.Update, bot core.MessageSender) {
+	usage := "Usage: /fx_ladder <currency> [quote currency] <first rung> <last rung> step <size>\n\n" +
+		"Example: /fx_ladder USD 1.34 1.36 step 0.005\n" +
+		"This will notify you once as the rate rises through each of 1.3400, 1.3450, ... 1.3600.\n\n" +
+		"Example: /fx_ladder USD 1.36 1.34 step 0.005\n" +
+		"This will notify you once as the rate falls through each rung."
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) < 4 {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, usage)
 		bot.Send(msg)
 		return
 	}
 
-	sub, err := schemas.CreateOrUpdateSubscription(update.Message.Chat.ID, currency, thresholdAbove, thresholdBelow, nil)
+	base, currency, rest, ok := resolvePair(update, bot, args)
+	if !ok {
+		return
+	}
+	rungs, err := parseLadder(rest)
 	if err != nil {
-		log.Error(err)
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			fmt.Sprintf("Error creating subscription: %v", err))
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("Invalid ladder: %v\n\n%s", err, usage))
 		bot.Send(msg)
 		return
 	}
 
-	var response string
-	if thresholdAbove != nil {
-		response = fmt.Sprintf("✅ Subscribed to %s/SGD notifications.\nYou will be notified when the rate goes *above* %.4f SGD.\n\nNote: This is a one-time notification and will be removed after triggered.", currency, *thresholdAbove)
-	} else {
-		response = fmt.Sprintf("✅ Subscribed to %s/SGD notifications.\nYou will be notified when the rate goes *below* %.4f SGD.\n\nNote: This is a one-time notification and will be removed after triggered.", currency, *thresholdBelow)
+	direction := schemas.TriggerAbove
+	if rungs[0] > rungs[len(rungs)-1] {
+		direction = schemas.TriggerBelow
 	}
-
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID, response)
-	msg.ParseMode = "Markdown"
+	lines, ok := addAlerts(update, bot, []*schemas.CurrencySubscription{
+		{ChatID: update.Message.Chat.ID, BaseCurrency: base, Currency: currency, Ladder: rungs, LadderDirection: direction},
+	})
+	if !ok {
+		return
+	}
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+		strings.Join(lines, "\n")+"\n\nYou will be notified once for each rung the rate crosses. Use /fx_list to see the rungs left.")
 	bot.Send(msg)
+}
 
-	currentRate, _, err := core.GetCurrentRate(currency)
-	if err == nil {
-		sub.LastNotifiedRate = currentRate
-		sub.Update()
+// parseLadder reads "<first> <last> step <size>" into the rungs from first to
+// last, in the order the rate will cross them.
+func parseLadder(args []string) ([]float64, error) {
+	if len(args) == 4 && strings.EqualFold(args[2], "step") {
+		args = []string{args[0], args[1], args[3]}
+	}
+	if len(args) != 3 {
+		return nil, errors.New("expected the first rung, the last rung and the step")
+	}
+	var values [3]float64
+	for i, arg := range args {
+		value, err := strconv.ParseFloat(arg, 64)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("%q is not a positive rate", arg)
+		}
+		values[i] = value
+	}
+	first, last, step := values[0], values[1], values[2]
+
+	count := int(math.Floor(math.Abs(last-first)/step+1e-9)) + 1
+	if count < 2 {
+		return nil, errors.New("the step must be smaller than the distance between the first and last rung")
+	}
+	if count > maxLadderRungs {
+		return nil, fmt.Errorf("a ladder can have at most %d rungs, this one has %d", maxLadderRungs, count)
+	}
+	direction := 1.0
+	if last < first {
+		direction = -1
 	}
+	rungs := make([]float64, count)
+	for i := range rungs {
+		rungs[i] = math.Round((first+direction*float64(i)*step)*1e8) / 1e8
+	}
+	return rungs, nil
 }
 
-func HandleFXIntervalCommand(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
+func HandleFXIndicatorCommand(update *tgbotapi.Update, bot core.MessageSender) {
+	usage := "Usage: /fx_indicator <currency> [quote currency] <indicator>\n\n" +
+		"Indicators:\n" + indicators.Usage + "\n\n" +
+		"Example: /fx_indicator USD sma 20 50 above\n" +
+		"This will notify you when the 20-day SMA crosses above the 50-day SMA.\n\n" +
+		"Example: /fx_indicator USD rsi 14 above 70\n" +
+		"This will notify you when the 14-day RSI rises through 70.\n\n" +
+		"Example: /fx_indicator USD bollinger 20 2\n" +
+		"This will notify you when the rate leaves the 2-sigma 20-day Bollinger band."
 	args := strings.Fields(update.Message.CommandArguments())
-	if len(args) < 2 {
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"Usage: /fx_interval <currency> <interval>\n\n"+
-				"Example: /fx_interval USD 0.05\n"+
-				"This will notify you every time the rate changes by 0.05 SGD or more.")
+	if len(args) < 3 {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, usage)
 		bot.Send(msg)
 		return
 	}
 
-	currency := strings.ToUpper(args[0])
-	if !utils.IsCurrencySupported(currency) {
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			fmt.Sprintf("Unsupported currency: %s\n\nSupported currencies: %s",
-				currency, strings.Join(utils.SupportedCurrencies, ", ")))
+	// sma, ema and rsi look like currency codes, so the pair is everything
+	// before the indicator name.
+	split := slices.IndexFunc(args, indicators.IsKind)
+	if split < 1 {
+		split = len(args)
+	}
+	base, currency, rest, ok := resolvePair(update, bot, args[:split])
+	if !ok {
+		return
+	}
+	indicator, err := indicators.ParseAlert(append(rest, args[split:]...))
+	if err != nil {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf("Invalid indicator: %v\n\n%s", err, usage))
 		bot.Send(msg)
 		return
 	}
 
-	interval, err := strconv.ParseFloat(args[1], 64)
-	if err != nil || interval <= 0 {
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"Please provide a valid positive number for the interval.\nExample: /fx_interval USD 0.05")
-		bot.Send(msg)
+	lines, ok := addAlerts(update, bot, []*schemas.CurrencySubscription{
+		{ChatID: update.Message.Chat.ID, BaseCurrency: base, Currency: currency, Indicator: &indicator},
+	})
+	if !ok {
 		return
 	}
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+		lines[0]+"\n\nIndicators are computed from daily rates, so you will be notified at most once per day, each time the signal occurs.")
+	bot.Send(msg)
+}
 
-	sub, err := schemas.CreateOrUpdateSubscription(update.Message.Chat.ID, currency, nil, nil, &interval)
+func HandleFXListCommand(update *tgbotapi.Update, bot core.MessageSender) {
+	subscriptions, err := store.Subscriptions.ListByChat(context.Background(), update.Message.Chat.ID)
 	if err != nil {
 		log.Error(err)
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			fmt.Sprintf("Error creating subscription: %v", err))
+			fmt.Sprintf("Error fetching subscriptions: %v", err))
 		bot.Send(msg)
 		return
 	}
 
+	active := make([]schemas.CurrencySubscription, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		if sub.Enabled {
+			active = append(active, sub)
+		}
+	}
 	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		fmt.Sprintf("✅ Subscribed to %s/SGD interval notifications.\nYou will be notified every time the rate changes by %.4f SGD or more.", currency, interval))
+		core.FormatSubscriptionListMessage(active))
 	bot.Send(msg)
+}
+
+const (
+	defaultAlertHistoryLimit = 10
+	maxAlertHistoryLimit     = 50
+)
 
-	currentRate, _, err := core.GetCurrentRate(currency)
-	if err == nil {
-		sub.LastNotifiedRate = currentRate
-		sub.Update()
+func HandleFXHistoryAlertsCommand(update *tgbotapi.Update, bot core.MessageSender) {
+	currency, limit := "", defaultAlertHistoryLimit
+	for _, arg := range strings.Fields(update.Message.CommandArguments()) {
+		if n, err := strconv.Atoi(arg); err == nil && n > 0 {
+			limit = min(n, maxAlertHistoryLimit)
+			continue
+		}
+		if !utils.IsCurrencySupported(arg) {
+			sendUnsupportedCurrency(update, bot, arg)
+			return
+		}
+		currency = strings.ToUpper(arg)
 	}
-}
 
-func HandleFXListCommand(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
-	subscriptions, err := schemas.GetCurrencySubscriptionsByChatID(update.Message.Chat.ID)
+	notifications, err := store.Notifications.ListByChat(context.Background(), update.Message.Chat.ID, currency, limit)
 	if err != nil {
 		log.Error(err)
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			fmt.Sprintf("Error fetching subscriptions: %v", err))
+			fmt.Sprintf("Error fetching alert history: %v", err))
 		bot.Send(msg)
 		return
 	}
 
+	timezone, err := time.LoadLocation(utils.DEFAULT_TIMEZONE)
+	if err != nil {
+		panic(err)
+	}
 	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		core.FormatSubscriptionListMessage(subscriptions))
+		core.FormatNotificationHistoryMessage(notifications, timezone))
 	bot.Send(msg)
 }
 
-func HandleFXUnsubscribeCommand(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
+func HandleFXUnsubscribeCommand(update *tgbotapi.Update, bot core.MessageSender) {
 	args := strings.Fields(update.Message.CommandArguments())
 	if len(args) == 0 {
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			"Usage: /fx_unsubscribe <currency>\nExample: /fx_unsubscribe USD")
+			"Usage: /fx_unsubscribe <alert id> [alert id...]\n"+
+				"       /fx_unsubscribe <currency> [quote currency]\n\n"+
+				"Example: /fx_unsubscribe 1a2b3c4d\n"+
+				"Example: /fx_unsubscribe USD (removes every USD alert)\n\n"+
+				"Use /fx_list to see your alert IDs.")
 		bot.Send(msg)
 		return
 	}
 
-	currency := strings.ToUpper(args[0])
-	if !utils.IsCurrencySupported(currency) {
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			fmt.Sprintf("Unsupported currency: %s\n\nSupported currencies: %s",
-				currency, strings.Join(utils.SupportedCurrencies, ", ")))
-		bot.Send(msg)
+	if isCurrencyCode(args[0]) {
+		unsubscribePair(update, bot, args)
 		return
 	}
 
-	sub, err := schemas.GetCurrencySubscription(update.Message.Chat.ID, currency)
+	lines := make([]string, 0, len(args))
+	for _, id := range args {
+		sub, err := store.FindSubscription(context.Background(), update.Message.Chat.ID, id)
+		if errors.Is(err, store.ErrAlertNotFound) || errors.Is(err, store.ErrAmbiguousAlertID) {
+			lines = append(lines, fmt.Sprintf("⚠️ %s: %v", id, err))
+			continue
+		}
+		if err == nil {
+			err = store.Subscriptions.Delete(context.Background(), sub.ID)
+		}
+		if err != nil {
+			log.Error(err)
+			lines = append(lines, fmt.Sprintf("⚠️ %s: error removing alert: %v", id, err))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("✅ Removed alert %s: %s", sub.ShortID(), sub.Describe()))
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID, strings.Join(lines, "\n"))
+	bot.Send(msg)
+}
+
+func unsubscribePair(update *tgbotapi.Update, bot core.MessageSender, args []string) {
+	base, currency, _, ok := resolvePair(update, bot, args)
+	if !ok {
+		return
+	}
+
+	subscriptions, err := store.ListPairSubscriptions(context.Background(), update.Message.Chat.ID, base, currency)
 	if err != nil {
 		log.Error(err)
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
@@ -271,22 +542,24 @@ func HandleFXUnsubscribeCommand(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
 		return
 	}
 
-	if sub == nil {
+	if len(subscriptions) == 0 {
 		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			fmt.Sprintf("You don't have a subscription for %s.", currency))
+			fmt.Sprintf("You don't have a subscription for %s/%s.", currency, base))
 		bot.Send(msg)
 		return
 	}
 
-	if err := sub.Delete(); err != nil {
-		log.Error(err)
-		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-			fmt.Sprintf("Error removing subscription: %v", err))
-		bot.Send(msg)
-		return
+	for _, sub := range subscriptions {
+		if err := store.Subscriptions.Delete(context.Background(), sub.ID); err != nil {
+			log.Error(err)
+			msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+				fmt.Sprintf("Error removing subscription: %v", err))
+			bot.Send(msg)
+			return
+		}
 	}
 
 	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
-		fmt.Sprintf("✅ Unsubscribed from %s/SGD notifications.", currency))
+		fmt.Sprintf("✅ Unsubscribed from %s/%s notifications (%d alerts removed).", currency, base, len(subscriptions)))
 	bot.Send(msg)
 }
@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/core"
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/store"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedRateProvider struct {
+	rates map[string]float64
+}
+
+func (p fixedRateProvider) Name() string { return "Fixed" }
+
+func (p fixedRateProvider) LatestRates(base string, currencies []string) (map[string]*schemas.ExchangeRate, error) {
+	quotes := make(map[string]*schemas.ExchangeRate)
+	for _, currency := range currencies {
+		if rate, ok := p.rates[currency]; ok {
+			quotes[currency] = &schemas.ExchangeRate{Base: base, Currency: currency, Rate: rate, Date: "2026-02-20", Source: p.Name()}
+		}
+	}
+	return quotes, nil
+}
+
+func (p fixedRateProvider) HistoricalRates(base string, currencies []string, start, end time.Time) (map[string][]schemas.HistoricalRate, error) {
+	return map[string][]schemas.HistoricalRate{}, nil
+}
+
+func (p fixedRateProvider) SupportedCurrencies() ([]schemas.CurrencyInfo, error) {
+	return nil, nil
+}
+
+type recordingSender struct {
+	sent []tgbotapi.Chattable
+}
+
+func (s *recordingSender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	s.sent = append(s.sent, c)
+	return tgbotapi.Message{}, nil
+}
+
+func (s *recordingSender) lastText() string {
+	if len(s.sent) == 0 {
+		return ""
+	}
+	if msg, ok := s.sent[len(s.sent)-1].(tgbotapi.MessageConfig); ok {
+		return msg.Text
+	}
+	return ""
+}
+
+func setupHandlerTest(t *testing.T) *recordingSender {
+	subscriptions, chatSettings, notifications := store.Subscriptions, store.ChatSettings, store.Notifications
+	provider := core.ActiveRateProvider
+	require.NoError(t, store.Configure(store.BackendMemory))
+	core.ActiveRateProvider = fixedRateProvider{rates: map[string]float64{"USD": 1.35, "EUR": 1.45}}
+	t.Cleanup(func() {
+		store.Subscriptions, store.ChatSettings, store.Notifications = subscriptions, chatSettings, notifications
+		core.ActiveRateProvider = provider
+	})
+	return &recordingSender{}
+}
+
+func commandUpdate(chatID int64, text string) *tgbotapi.Update {
+	command := text
+	for i, r := range text {
+		if r == ' ' {
+			command = text[:i]
+			break
+		}
+	}
+	return &tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: chatID},
+			From:     &tgbotapi.User{UserName: "tester"},
+			Text:     text,
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}},
+		},
+	}
+}
+
+func TestHandleFXSubscribeCommand(t *testing.T) {
+	sender := setupHandlerTest(t)
+
+	HandleFXSubscribeCommand(commandUpdate(1, "/fx_subscribe USD -above 1.40"), sender)
+	assert.Contains(t, sender.lastText(), "added: USD/SGD above 1.4000 SGD")
+	HandleFXSubscribeCommand(commandUpdate(1, "/fx_subscribe USD -above 1.45"), sender)
+	HandleFXSubscribeCommand(commandUpdate(1, "/fx_subscribe USD -above 1.40"), sender)
+	assert.Contains(t, sender.lastText(), "already exists")
+
+	subscriptions, err := store.ListPairSubscriptions(context.Background(), 1, "SGD", "USD")
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 2)
+	assert.ElementsMatch(t, []float64{1.40, 1.45}, []float64{*subscriptions[0].ThresholdAbove, *subscriptions[1].ThresholdAbove})
+	assert.Equal(t, 1.35, subscriptions[0].LastNotifiedRate)
+
+	HandleFXSubscribeCommand(commandUpdate(1, "/fx_subscribe USD"), sender)
+	assert.Contains(t, sender.lastText(), "Please specify -above or -below")
+}
+
+func TestHandleFXSubscribeCommand_Percentages(t *testing.T) {
+	sender := setupHandlerTest(t)
+
+	HandleFXSubscribeCommand(commandUpdate(1, "/fx_subscribe USD -above +2% -below -3%"), sender)
+	assert.Contains(t, sender.lastText(), "above 1.3770 SGD (+2.00%)")
+	assert.Contains(t, sender.lastText(), "below 1.3095 SGD (-3.00%)")
+	HandleFXIntervalCommand(commandUpdate(1, "/fx_interval EUR 1%"), sender)
+	assert.Contains(t, sender.lastText(), "every 1.00% change")
+	assert.Contains(t, sender.lastText(), "changes by 1.00% or more")
+
+	subscriptions, err := store.ListPairSubscriptions(context.Background(), 1, "SGD", "USD")
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 2)
+	above := slices.IndexFunc(subscriptions, func(sub schemas.CurrencySubscription) bool { return sub.ThresholdAbovePercent != nil })
+	require.GreaterOrEqual(t, above, 0)
+	assert.Equal(t, 2.0, *subscriptions[above].ThresholdAbovePercent)
+	assert.Equal(t, -3.0, *subscriptions[1-above].ThresholdBelowPercent)
+	assert.Equal(t, 1.35, subscriptions[above].ReferenceRate)
+
+	HandleFXListCommand(commandUpdate(1, "/fx_list"), sender)
+	assert.Contains(t, sender.lastText(), "Alert above: +2.00% of 1.3500 → 1.3770 SGD")
+	assert.Contains(t, sender.lastText(), "Interval: 1.00% (currently 0.0145 SGD from 1.4500)")
+
+	HandleFXSubscribeCommand(commandUpdate(1, "/fx_subscribe USD -above -2%"), sender)
+	assert.Contains(t, sender.lastText(), "Please specify -above or -below")
+}
+
+func TestHandleFXSubscribeCommand_Repeat(t *testing.T) {
+	sender := setupHandlerTest(t)
+
+	HandleFXSubscribeCommand(commandUpdate(1, "/fx_subscribe USD -above 1.40 -repeat"), sender)
+	assert.Contains(t, sender.lastText(), "above 1.4000 SGD, repeating (band 0.50%)")
+	assert.Contains(t, sender.lastText(), "Repeating alerts stay active")
+	HandleFXSubscribeCommand(commandUpdate(1, "/fx_subscribe USD -repeat 0.02 -below 1.30"), sender)
+	assert.Contains(t, sender.lastText(), "below 1.3000 SGD, repeating (band 0.0200 SGD)")
+
+	HandleFXListCommand(commandUpdate(1, "/fx_list"), sender)
+	assert.Contains(t, sender.lastText(), "🔁 repeats, re-arms below 1.3930")
+	assert.Contains(t, sender.lastText(), "🔁 repeats, re-arms above 1.3200")
+}
+
+func TestHandleFXLadderCommand(t *testing.T) {
+	sender := setupHandlerTest(t)
+
+	HandleFXLadderCommand(commandUpdate(1, "/fx_ladder USD 1.34 1.36 step 0.005"), sender)
+	assert.Contains(t, sender.lastText(), "added: USD/SGD ladder above 1.3550 → 1.3600 SGD (2 rungs)")
+	assert.Contains(t, sender.lastText(), "Skipped 3 rungs the rate of 1.3500 has already passed")
+
+	HandleFXLadderCommand(commandUpdate(1, "/fx_ladder EUR USD 1.2 1.1 step 0.05"), sender)
+	subscriptions, err := store.ListPairSubscriptions(context.Background(), 1, "USD", "EUR")
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, []float64{1.2, 1.15, 1.1}, subscriptions[0].Ladder)
+	assert.Equal(t, schemas.TriggerBelow, subscriptions[0].LadderDirection)
+
+	HandleFXListCommand(commandUpdate(1, "/fx_list"), sender)
+	assert.Contains(t, sender.lastText(), "Ladder above: next 1.3550 SGD, 2 rungs left up to 1.3600 SGD")
+
+	HandleFXLadderCommand(commandUpdate(1, "/fx_ladder USD 1.30 1.32 step 0.01"), sender)
+	assert.Contains(t, sender.lastText(), "past every rung")
+	HandleFXLadderCommand(commandUpdate(1, "/fx_ladder USD 1 2 step 0.001"), sender)
+	assert.Contains(t, sender.lastText(), "at most 50 rungs")
+}
+
+func TestHandleFXIndicatorCommand(t *testing.T) {
+	sender := setupHandlerTest(t)
+
+	HandleFXIndicatorCommand(commandUpdate(1, "/fx_indicator USD sma 20 50 above"), sender)
+	assert.Contains(t, sender.lastText(), "added: USD/SGD when 20-day SMA crosses above 50-day SMA")
+
+	HandleFXIndicatorCommand(commandUpdate(1, "/fx_indicator EUR USD bollinger 20 2"), sender)
+	subscriptions, err := store.ListPairSubscriptions(context.Background(), 1, "USD", "EUR")
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, &schemas.IndicatorAlert{Kind: schemas.IndicatorBollinger, Period: 20, Deviations: 2}, subscriptions[0].Indicator)
+
+	HandleFXListCommand(commandUpdate(1, "/fx_list"), sender)
+	assert.Contains(t, sender.lastText(), "Indicator: 20-day SMA crosses above 50-day SMA")
+	assert.Contains(t, sender.lastText(), "Indicator: rate leaves either 2-sigma 20-day Bollinger band")
+
+	HandleFXIndicatorCommand(commandUpdate(1, "/fx_indicator USD sma 50 20 above"), sender)
+	assert.Contains(t, sender.lastText(), "Invalid indicator: the fast period 50 must be shorter than the slow period 20")
+	HandleFXIndicatorCommand(commandUpdate(1, "/fx_indicator USD macd 12 26"), sender)
+	assert.Contains(t, sender.lastText(), `unknown indicator "macd"`)
+}
+
+func TestHandleFXUnsubscribeCommand(t *testing.T) {
+	sender := setupHandlerTest(t)
+
+	HandleFXUnsubscribeCommand(commandUpdate(1, "/fx_unsubscribe USD"), sender)
+	assert.Contains(t, sender.lastText(), "don't have a subscription for USD/SGD")
+
+	HandleFXIntervalCommand(commandUpdate(1, "/fx_interval USD 0.05"), sender)
+	HandleFXUnsubscribeCommand(commandUpdate(1, "/fx_unsubscribe USD"), sender)
+	assert.Contains(t, sender.lastText(), "Unsubscribed from USD/SGD")
+
+	subscriptions, err := store.Subscriptions.ListByChat(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Empty(t, subscriptions)
+}
+
+func TestHandleFXUnsubscribeCommand_ByID(t *testing.T) {
+	sender := setupHandlerTest(t)
+
+	HandleFXSubscribeCommand(commandUpdate(1, "/fx_subscribe USD -above 1.40 -below 1.30"), sender)
+	subscriptions, err := store.ListPairSubscriptions(context.Background(), 1, "SGD", "USD")
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 2)
+
+	HandleFXListCommand(commandUpdate(1, "/fx_list"), sender)
+	assert.Contains(t, sender.lastText(), "["+subscriptions[0].ShortID()+"]")
+
+	HandleFXUnsubscribeCommand(commandUpdate(1, "/fx_unsubscribe "+subscriptions[0].ShortID()), sender)
+	assert.Contains(t, sender.lastText(), "Removed alert "+subscriptions[0].ShortID())
+
+	HandleFXUnsubscribeCommand(commandUpdate(2, "/fx_unsubscribe "+subscriptions[1].ShortID()), sender)
+	assert.Contains(t, sender.lastText(), "no alert with that ID")
+
+	remaining, err := store.Subscriptions.ListByChat(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, subscriptions[1].ID, remaining[0].ID)
+}
+
+func TestHandleSettingsCommand_UsesChatBase(t *testing.T) {
+	sender := setupHandlerTest(t)
+
+	HandleSettingsCommand(commandUpdate(1, "/settings base MYR"), sender)
+	assert.Contains(t, sender.lastText(), "Home currency set to MYR")
+
+	HandleFXSubscribeCommand(commandUpdate(1, "/fx_subscribe USD -below 3.00"), sender)
+	subscriptions, err := store.ListPairSubscriptions(context.Background(), 1, "MYR", "USD")
+	require.NoError(t, err)
+	assert.Len(t, subscriptions, 1)
+}
+
+func TestHandleFXHistoryAlertsCommand(t *testing.T) {
+	sender := setupHandlerTest(t)
+	ctx := context.Background()
+
+	HandleFXHistoryAlertsCommand(commandUpdate(1, "/fx_history_alerts"), sender)
+	assert.Contains(t, sender.lastText(), "No alerts have been sent")
+
+	sentAt := time.Date(2026, 2, 17, 1, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Notifications.Create(ctx, &schemas.Notification{ChatID: 1, BaseCurrency: "SGD", Currency: "USD", Rate: 1.295,
+		Trigger: schemas.TriggerBelow, DataDate: "2026-02-16", Status: schemas.NotificationDelivered, SentAt: sentAt}))
+	require.NoError(t, store.Notifications.Create(ctx, &schemas.Notification{ChatID: 1, BaseCurrency: "SGD", Currency: "EUR", Rate: 1.45,
+		Trigger: schemas.TriggerInterval, Status: schemas.NotificationFailed, Error: "Forbidden", SentAt: sentAt.Add(time.Hour)}))
+
+	HandleFXHistoryAlertsCommand(commandUpdate(1, "/fx_history_alerts usd"), sender)
+	text := sender.lastText()
+	assert.Contains(t, text, "2026-02-17 09:00 USD/SGD fell below threshold at 1.2950 SGD (data 2026-02-16) ✅")
+	assert.NotContains(t, text, "EUR")
+
+	HandleFXHistoryAlertsCommand(commandUpdate(1, "/fx_history_alerts 1"), sender)
+	assert.Contains(t, sender.lastText(), "EUR/SGD moved by interval at 1.4500 SGD ⚠️ not delivered: Forbidden")
+	assert.NotContains(t, sender.lastText(), "USD")
+}
+
+func TestAddAlerts_RefusesAlertsThatStartFromAnUnavailableRate(t *testing.T) {
+	sender := setupHandlerTest(t)
+
+	HandleFXIntervalCommand(commandUpdate(1, "/fx_interval JPY 0.05"), sender)
+	assert.Contains(t, sender.lastText(), "Error fetching the current JPY/SGD rate for this alert to start from")
+	HandleFXLadderCommand(commandUpdate(1, "/fx_ladder JPY 0.009 0.01 step 0.0005"), sender)
+	assert.Contains(t, sender.lastText(), "Error fetching the current JPY/SGD rate for this alert to start from")
+	HandleFXSubscribeCommand(commandUpdate(1, "/fx_subscribe JPY -above 0.01"), sender)
+	assert.Contains(t, sender.lastText(), "added: JPY/SGD above 0.0100 SGD")
+
+	subscriptions, err := store.ListPairSubscriptions(context.Background(), 1, "SGD", "JPY")
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Nil(t, subscriptions[0].Interval)
+	assert.Empty(t, subscriptions[0].Ladder)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/core"
+	"github.com/Jason-CKY/telegram-notifybot/internal/store"
+	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
+	log "github.com/sirupsen/logrus"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+func splitPairArgs(args []string) (currency, base string, rest []string) {
+	if len(args) == 0 {
+		return "", "", nil
+	}
+	currency = strings.ToUpper(args[0])
+	if len(args) > 1 && isCurrencyCode(args[1]) {
+		return currency, strings.ToUpper(args[1]), args[2:]
+	}
+	return currency, "", args[1:]
+}
+
+func sendUnsupportedCurrency(update *tgbotapi.Update, bot core.MessageSender, currency string) {
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+		fmt.Sprintf("Unsupported currency: %s\n\nSupported currencies: %s",
+			currency, strings.Join(utils.SupportedCurrencyCodes(), ", ")))
+	bot.Send(msg)
+}
+
+func getChatBaseCurrency(update *tgbotapi.Update, bot core.MessageSender) (string, bool) {
+	base, err := store.GetChatBaseCurrency(context.Background(), update.Message.Chat.ID)
+	if err != nil {
+		log.Error(err)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+			fmt.Sprintf("Error fetching chat settings: %v", err))
+		bot.Send(msg)
+		return "", false
+	}
+	return base, true
+}
+
+func resolvePair(update *tgbotapi.Update, bot core.MessageSender, args []string) (base, currency string, rest []string, ok bool) {
+	currency, base, rest = splitPairArgs(args)
+	if !utils.IsCurrencySupported(currency) {
+		sendUnsupportedCurrency(update, bot, currency)
+		return "", "", nil, false
+	}
+
+	if base == "" {
+		if base, ok = getChatBaseCurrency(update, bot); !ok {
+			return "", "", nil, false
+		}
+	} else if !utils.IsCurrencySupported(base) {
+		sendUnsupportedCurrency(update, bot, base)
+		return "", "", nil, false
+	}
+
+	if currency == base {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+			fmt.Sprintf("Cannot quote %s against itself. Name a second currency (e.g. /fx %s USD) or change your home currency with /settings base <currency>.", currency, currency))
+		bot.Send(msg)
+		return "", "", nil, false
+	}
+
+	return base, currency, rest, true
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitPairArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		currency string
+		base     string
+		rest     []string
+	}{
+		{[]string{"usd"}, "USD", "", []string{}},
+		{[]string{"EUR", "usd"}, "EUR", "USD", []string{}},
+		{[]string{"USD", "6"}, "USD", "", []string{"6"}},
+		{[]string{"JPY", "MYR", "6"}, "JPY", "MYR", []string{"6"}},
+		{[]string{"USD", "-above", "1.40"}, "USD", "", []string{"-above", "1.40"}},
+		{[]string{"EUR", "USD", "-below", "1.05"}, "EUR", "USD", []string{"-below", "1.05"}},
+		{[]string{}, "", "", nil},
+	}
+
+	for _, tt := range tests {
+		currency, base, rest := splitPairArgs(tt.args)
+		assert.Equal(t, tt.currency, currency, "splitPairArgs(%v) currency", tt.args)
+		assert.Equal(t, tt.base, base, "splitPairArgs(%v) base", tt.args)
+		assert.Equal(t, tt.rest, rest, "splitPairArgs(%v) rest", tt.args)
+	}
+}
@@ -1,14 +1,17 @@
 package handler
 
 import (
-	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"context"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/core"
+	"github.com/Jason-CKY/telegram-notifybot/internal/store"
 	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
 	log "github.com/sirupsen/logrus"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func HandleUpdate(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
+func HandleUpdate(update *tgbotapi.Update, bot core.MessageSender) {
 	if update.Message != nil && utils.IsUsernameAllowed(update.Message.From.UserName) {
 		if update.Message.IsCommand() {
 			HandleCommand(update, bot)
@@ -16,19 +19,24 @@ func HandleUpdate(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
 	}
 }
 
-func HandleCommand(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
+func HandleCommand(update *tgbotapi.Update, bot core.MessageSender) {
 	msg := tgbotapi.NewMessage(update.Message.Chat.ID, "")
 
 	switch update.Message.Command() {
 	case "help":
-		msg.Text = utils.HELP_MESSAGE
+		msg.Text = utils.HelpMessage()
 	case "start":
-		_, _, err := schemas.InsertChatSettingsIfNotPresent(update.Message.Chat.ID)
+		_, _, err := store.InsertChatSettingsIfNotPresent(context.Background(), update.Message.Chat.ID)
 		if err != nil {
 			log.Error(err)
 			return
 		}
 		msg.Text = "Welcome to NotifyBot! Use /help to see available commands."
+	case "currencies":
+		msg.Text = utils.CurrencyListMessage()
+	case "settings":
+		HandleSettingsCommand(update, bot)
+		return
 	case "fx":
 		HandleFXCommand(update, bot)
 		return
@@ -41,9 +49,24 @@ func HandleCommand(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
 	case "fx_interval":
 		HandleFXIntervalCommand(update, bot)
 		return
+	case "fx_ladder":
+		HandleFXLadderCommand(update, bot)
+		return
+	case "fx_indicator":
+		HandleFXIndicatorCommand(update, bot)
+		return
 	case "fx_list":
 		HandleFXListCommand(update, bot)
 		return
+	case "fx_history_alerts":
+		HandleFXHistoryAlertsCommand(update, bot)
+		return
+	case "fx_export":
+		HandleFXExportCommand(update, bot)
+		return
+	case "fx_import":
+		HandleFXImportCommand(update, bot)
+		return
 	case "fx_unsubscribe":
 		HandleFXUnsubscribeCommand(update, bot)
 		return
@@ -51,7 +74,7 @@ func HandleCommand(update *tgbotapi.Update, bot *tgbotapi.BotAPI) {
 		return
 	}
 
-	if _, err := bot.Request(msg); err != nil {
+	if _, err := bot.Send(msg); err != nil {
 		log.Error(err)
 		return
 	}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/core"
+	"github.com/Jason-CKY/telegram-notifybot/internal/store"
+	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
+	log "github.com/sirupsen/logrus"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func HandleSettingsCommand(update *tgbotapi.Update, bot core.MessageSender) {
+	args := strings.Fields(update.Message.CommandArguments())
+	if len(args) == 0 {
+		base, ok := getChatBaseCurrency(update, bot)
+		if !ok {
+			return
+		}
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+			fmt.Sprintf("⚙️ Chat Settings\n\nHome currency: %s\n\nUsage: /settings base <currency>\nExample: /settings base MYR", base))
+		bot.Send(msg)
+		return
+	}
+
+	if strings.ToLower(args[0]) != "base" || len(args) < 2 {
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+			"Usage: /settings base <currency>\nExample: /settings base MYR")
+		bot.Send(msg)
+		return
+	}
+
+	base := strings.ToUpper(args[1])
+	if !utils.IsCurrencySupported(base) {
+		sendUnsupportedCurrency(update, bot, base)
+		return
+	}
+
+	if _, err := store.SetChatBaseCurrency(context.Background(), update.Message.Chat.ID, base); err != nil {
+		log.Error(err)
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+			fmt.Sprintf("Error updating settings: %v", err))
+		bot.Send(msg)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(update.Message.Chat.ID,
+		fmt.Sprintf("✅ Home currency set to %s.\n\nNew queries and subscriptions will be quoted in %s. Existing subscriptions keep their original home currency.", base, base))
+	bot.Send(msg)
+}
@@ -0,0 +1,188 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+type Config struct {
+	Timeout          time.Duration
+	MaxRetries       int
+	BaseDelay        time.Duration
+	MaxDelay         time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Timeout:          30 * time.Second,
+		MaxRetries:       3,
+		BaseDelay:        500 * time.Millisecond,
+		MaxDelay:         10 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  time.Minute,
+	}
+}
+
+var Default = New(DefaultConfig())
+
+type Client struct {
+	HTTP   *http.Client
+	Config Config
+
+	now      func() time.Time
+	sleep    func(ctx context.Context, d time.Duration) error
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+func New(cfg Config) *Client {
+	return &Client{
+		HTTP:     &http.Client{Timeout: cfg.Timeout},
+		Config:   cfg,
+		now:      time.Now,
+		sleep:    sleepContext,
+		breakers: make(map[string]*breaker),
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *Client) breakerFor(host string) *breaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.breakers[host]
+	if !ok {
+		b = &breaker{host: host, threshold: c.Config.BreakerThreshold, cooldown: c.Config.BreakerCooldown}
+		c.breakers[host] = b
+	}
+	return b
+}
+
+// PATCH is retried like the other idempotent methods. Versioned subscription
+// updates are not idempotent, so the Directus store checks whether a retried
+// update that reports a conflict was already applied.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut,
+		http.MethodDelete, http.MethodPatch, "SEARCH":
+		return true
+	}
+	return false
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// shouldRetry reports whether the attempt failed in a way worth retrying.
+// Non-idempotent requests are only retried when the server cannot have acted
+// on them.
+func shouldRetry(method string, res *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return false
+		}
+		return isIdempotent(method) || isDialError(err)
+	}
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests, res.StatusCode == http.StatusServiceUnavailable:
+		return true
+	case res.StatusCode >= 500:
+		return isIdempotent(method)
+	}
+	return false
+}
+
+func isFailure(res *http.Response, err error) bool {
+	return err != nil || res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests
+}
+
+func (c *Client) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			delay := time.Duration(seconds) * time.Second
+			if delay > c.Config.MaxDelay {
+				delay = c.Config.MaxDelay
+			}
+			return delay
+		}
+	}
+
+	delay := c.Config.BaseDelay << attempt
+	if delay <= 0 || delay > c.Config.MaxDelay {
+		delay = c.Config.MaxDelay
+	}
+	// jitter between half and the full delay so concurrent callers spread out
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	b := c.breakerFor(host)
+
+	for attempt := 0; ; attempt++ {
+		if !b.allow(c.now()) {
+			return nil, fmt.Errorf("%w for %s", ErrCircuitOpen, host)
+		}
+
+		attemptReq := req
+		if attempt > 0 && req.Body != nil {
+			if req.GetBody == nil {
+				return nil, fmt.Errorf("cannot retry %s %s: request body is not replayable", req.Method, req.URL)
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		res, err := c.HTTP.Do(attemptReq)
+		if isFailure(res, err) {
+			b.failure(c.now())
+		} else {
+			b.success()
+		}
+
+		if attempt >= c.Config.MaxRetries || !shouldRetry(req.Method, res, err) {
+			return res, err
+		}
+
+		delay := c.backoff(attempt, res)
+		if err != nil {
+			log.Warnf("%s %s failed, retrying in %v (attempt %d/%d): %v", req.Method, req.URL.Redacted(), delay, attempt+1, c.Config.MaxRetries, err)
+		} else {
+			log.Warnf("%s %s returned status %d, retrying in %v (attempt %d/%d)", req.Method, req.URL.Redacted(), res.StatusCode, delay, attempt+1, c.Config.MaxRetries)
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		if err := c.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(cfg Config, now *time.Time) (*Client, *[]time.Duration) {
+	client := New(cfg)
+	delays := &[]time.Duration{}
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		return nil
+	}
+	if now != nil {
+		client.now = func() time.Time { return *now }
+	}
+	return client, delays
+}
+
+func statusSequence(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(calls.Add(1)) - 1
+		if i >= len(statuses) {
+			i = len(statuses) - 1
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(statuses[i])
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestClient_RetriesServerErrors(t *testing.T) {
+	server, calls := statusSequence(t, 500, 502, 200)
+	client, delays := newTestClient(DefaultConfig(), nil)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+
+	require.Len(t, *delays, 2)
+	assert.GreaterOrEqual(t, (*delays)[0], 250*time.Millisecond)
+	assert.LessOrEqual(t, (*delays)[0], 500*time.Millisecond)
+	assert.GreaterOrEqual(t, (*delays)[1], 500*time.Millisecond)
+	assert.LessOrEqual(t, (*delays)[1], time.Second)
+}
+
+func TestClient_ReturnsLastResponseWhenRetriesExhausted(t *testing.T) {
+	server, calls := statusSequence(t, 503)
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 2
+	client, _ := newTestClient(cfg, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, 503, res.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	server, calls := statusSequence(t, 404)
+	client, _ := newTestClient(DefaultConfig(), nil)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, 404, res.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_HonoursRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client, delays := newTestClient(DefaultConfig(), nil)
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString("{}"))
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, []time.Duration{2 * time.Second}, *delays)
+}
+
+func TestClient_ReplaysBodyAndSkipsAmbiguousPostRetries(t *testing.T) {
+	server, calls := statusSequence(t, 503, 200)
+	client, _ := newTestClient(DefaultConfig(), nil)
+
+	req, _ := http.NewRequest("SEARCH", server.URL, bytes.NewBufferString(`{"query":{}}`))
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	assert.Equal(t, `{"query":{}}`, string(body))
+	assert.Equal(t, int32(2), calls.Load())
+
+	server, calls = statusSequence(t, 500, 200)
+	req, _ = http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString("{}"))
+	res, err = client.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, 500, res.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_CircuitBreakerOpensAndRecovers(t *testing.T) {
+	server, calls := statusSequence(t, 500, 500, 500, 200)
+	now := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 0
+	cfg.BreakerThreshold = 3
+	client, _ := newTestClient(cfg, &now)
+
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		assert.Equal(t, 500, res.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	_, err := client.Do(req)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int32(3), calls.Load())
+
+	now = now.Add(cfg.BreakerCooldown)
+	req, _ = http.NewRequest(http.MethodGet, server.URL, nil)
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	assert.Equal(t, stateClosed, client.breakerFor(req.URL.Host).state)
+}
+
+func TestClient_FailedTrialReopensBreaker(t *testing.T) {
+	server, _ := statusSequence(t, 500)
+	now := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 0
+	cfg.BreakerThreshold = 1
+	client, _ := newTestClient(cfg, &now)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	client.Do(req)
+
+	now = now.Add(cfg.BreakerCooldown)
+	req, _ = http.NewRequest(http.MethodGet, server.URL, nil)
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, 500, res.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL, nil)
+	_, err = client.Do(req)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+}
+
+func TestClient_RetriesStopWhenBreakerOpens(t *testing.T) {
+	server, calls := statusSequence(t, 502)
+	cfg := DefaultConfig()
+	cfg.MaxRetries = 5
+	cfg.BreakerThreshold = 2
+	client, _ := newTestClient(cfg, nil)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	_, err := client.Do(req)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int32(2), calls.Load())
+}
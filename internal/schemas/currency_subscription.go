@@ -1,12 +1,11 @@
 package schemas
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
@@ -15,6 +14,7 @@ import (
 type CurrencySubscription struct {
 	ID                   string    `json:"id,omitempty"`
 	ChatID               int64     `json:"chat_id"`
+	BaseCurrency         string    `json:"base_currency,omitempty"`
 	Currency             string    `json:"currency"`
 	ThresholdAbove       *float64  `json:"threshold_above"`
 	ThresholdBelow       *float64  `json:"threshold_below"`
@@ -22,8 +22,37 @@ type CurrencySubscription struct {
 	LastNotifiedRate     float64   `json:"last_notified_rate"`
 	LastNotificationTime time.Time `json:"last_notification_time"`
 	Enabled              bool      `json:"enabled"`
+	Version              int       `json:"version"`
+
+	// Percentage alerts are relative to ReferenceRate, the rate when the
+	// alert was created. ThresholdBelowPercent is negative. IntervalPercent
+	// is relative to LastNotifiedRate.
+	ThresholdAbovePercent *float64 `json:"threshold_above_percent"`
+	ThresholdBelowPercent *float64 `json:"threshold_below_percent"`
+	IntervalPercent       *float64 `json:"interval_percent"`
+	ReferenceRate         float64  `json:"reference_rate"`
+
+	// Repeating thresholds stay armed after firing. Triggered records the
+	// threshold that last fired until the rate moves back past it by the
+	// hysteresis band, given in the home currency or as a percentage of the
+	// threshold.
+	Repeat            bool     `json:"repeat"`
+	Hysteresis        *float64 `json:"hysteresis"`
+	HysteresisPercent *float64 `json:"hysteresis_percent"`
+	Triggered         string   `json:"triggered"`
+
+	// Ladder holds the rungs that have not been crossed yet, in the order the
+	// rate reaches them. LadderDirection is TriggerAbove for a rising ladder
+	// and TriggerBelow for a falling one.
+	Ladder          []float64 `json:"ladder"`
+	LadderDirection string    `json:"ladder_direction"`
+
+	Indicator *IndicatorAlert `json:"indicator"`
 }
 
+// DefaultHysteresisPercent is the band -repeat uses when none is given.
+const DefaultHysteresisPercent = 0.5
+
 func (cs CurrencySubscription) MarshalJSON() ([]byte, error) {
 	type Alias CurrencySubscription
 
@@ -59,250 +88,321 @@ func (cs *CurrencySubscription) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func (sub *CurrencySubscription) Create() error {
-	endpoint := fmt.Sprintf("%v/items/notifybot_currency_subscriptions", utils.DirectusHost)
-	reqBody, _ := json.Marshal(sub)
-	req, httpErr := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(reqBody))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", utils.DirectusToken))
-	if httpErr != nil {
-		return httpErr
-	}
-	client := &http.Client{}
-	res, httpErr := client.Do(req)
-	if httpErr != nil {
-		return httpErr
-	}
-	body, _ := io.ReadAll(res.Body)
-	defer res.Body.Close()
-	if res.StatusCode != 200 && res.StatusCode != 201 {
-		return fmt.Errorf("error creating subscription: %v", string(body))
-	}
-	var response struct {
-		Data CurrencySubscription `json:"data"`
-	}
-	if err := json.Unmarshal(body, &response); err != nil {
-		return err
+func (sub *CurrencySubscription) Base() string {
+	if sub.BaseCurrency == "" {
+		return utils.DEFAULT_BASE_CURRENCY
 	}
-	sub.ID = response.Data.ID
-	return nil
+	return sub.BaseCurrency
 }
 
-func (sub *CurrencySubscription) Update() error {
-	if sub.ID == "" {
-		return fmt.Errorf("cannot update subscription without ID")
-	}
-	endpoint := fmt.Sprintf("%v/items/notifybot_currency_subscriptions/%v", utils.DirectusHost, sub.ID)
-	reqBody, _ := json.Marshal(sub)
-	req, httpErr := http.NewRequest(http.MethodPatch, endpoint, bytes.NewBuffer(reqBody))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", utils.DirectusToken))
-	if httpErr != nil {
-		return httpErr
+func (sub *CurrencySubscription) Pair() string {
+	return fmt.Sprintf("%s/%s", sub.Currency, sub.Base())
+}
+
+const ShortIDLength = 8
+
+func (sub *CurrencySubscription) ShortID() string {
+	if len(sub.ID) <= ShortIDLength {
+		return sub.ID
 	}
-	client := &http.Client{}
-	res, httpErr := client.Do(req)
-	if httpErr != nil {
-		return httpErr
+	return sub.ID[:ShortIDLength]
+}
+
+func (sub *CurrencySubscription) SameAlert(other *CurrencySubscription) bool {
+	sameValue := func(a, b *float64) bool {
+		return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+	}
+	return sub.ChatID == other.ChatID && sub.Base() == other.Base() && sub.Currency == other.Currency &&
+		sameValue(sub.ThresholdAbove, other.ThresholdAbove) &&
+		sameValue(sub.ThresholdBelow, other.ThresholdBelow) &&
+		sameValue(sub.Interval, other.Interval) &&
+		sameValue(sub.ThresholdAbovePercent, other.ThresholdAbovePercent) &&
+		sameValue(sub.ThresholdBelowPercent, other.ThresholdBelowPercent) &&
+		sameValue(sub.IntervalPercent, other.IntervalPercent) &&
+		sub.Repeat == other.Repeat &&
+		sameValue(sub.Hysteresis, other.Hysteresis) &&
+		sameValue(sub.HysteresisPercent, other.HysteresisPercent) &&
+		sub.LadderDirection == other.LadderDirection && slices.Equal(sub.Ladder, other.Ladder) &&
+		((sub.Indicator == nil && other.Indicator == nil) ||
+			(sub.Indicator != nil && other.Indicator != nil && sub.Indicator.SameSignal(*other.Indicator)))
+}
+
+// hasConditions reports whether the alert still has anything to watch.
+func (sub *CurrencySubscription) hasConditions() bool {
+	return sub.ThresholdAbove != nil || sub.ThresholdBelow != nil || sub.Interval != nil ||
+		sub.ThresholdAbovePercent != nil || sub.ThresholdBelowPercent != nil || sub.IntervalPercent != nil ||
+		len(sub.Ladder) > 0 || sub.Indicator != nil
+}
+
+// CrossedRungs returns the ladder rungs currentRate has reached.
+func (sub *CurrencySubscription) CrossedRungs(currentRate float64) []float64 {
+	var crossed []float64
+	for _, rung := range sub.Ladder {
+		reached := (sub.LadderDirection == TriggerAbove && currentRate >= rung) ||
+			(sub.LadderDirection == TriggerBelow && currentRate <= rung)
+		if !reached {
+			break
+		}
+		crossed = append(crossed, rung)
 	}
-	body, _ := io.ReadAll(res.Body)
-	defer res.Body.Close()
-	if res.StatusCode != 200 {
-		return fmt.Errorf("error updating subscription: %v", string(body))
+	return crossed
+}
+
+// DropCrossedRungs removes the rungs currentRate has reached from the ladder
+// and returns them. The ladder is copied rather than modified in place, since
+// copies of a subscription share it.
+func (sub *CurrencySubscription) DropCrossedRungs(currentRate float64) []float64 {
+	crossed := sub.CrossedRungs(currentRate)
+	if len(crossed) > 0 {
+		sub.Ladder = slices.Clone(sub.Ladder[len(crossed):])
+	}
+	return crossed
+}
+
+func (sub *CurrencySubscription) UsesPercentage() bool {
+	return sub.ThresholdAbovePercent != nil || sub.ThresholdBelowPercent != nil || sub.IntervalPercent != nil
+}
+
+func (sub *CurrencySubscription) HasInterval() bool {
+	return sub.Interval != nil || sub.IntervalPercent != nil
+}
+
+// NeedsCurrentRate reports whether the alert starts from the rate when it is
+// added: percentages resolve against it, intervals count from it and ladders
+// drop the rungs it has already passed.
+func (sub *CurrencySubscription) NeedsCurrentRate() bool {
+	return sub.UsesPercentage() || sub.HasInterval() || len(sub.Ladder) > 0
+}
+
+// AboveLevel returns the rate the alert fires at or above, resolving a
+// percentage threshold against ReferenceRate.
+func (sub *CurrencySubscription) AboveLevel() *float64 {
+	if sub.ThresholdAbove != nil {
+		return sub.ThresholdAbove
 	}
-	return nil
+	return sub.percentLevel(sub.ThresholdAbovePercent)
 }
 
-func (sub *CurrencySubscription) Delete() error {
-	if sub.ID == "" {
-		return fmt.Errorf("cannot delete subscription without ID")
+// BelowLevel returns the rate the alert fires at or below, resolving a
+// percentage threshold against ReferenceRate.
+func (sub *CurrencySubscription) BelowLevel() *float64 {
+	if sub.ThresholdBelow != nil {
+		return sub.ThresholdBelow
 	}
-	endpoint := fmt.Sprintf("%v/items/notifybot_currency_subscriptions/%v", utils.DirectusHost, sub.ID)
-	req, httpErr := http.NewRequest(http.MethodDelete, endpoint, nil)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", utils.DirectusToken))
-	if httpErr != nil {
-		return httpErr
+	return sub.percentLevel(sub.ThresholdBelowPercent)
+}
+
+func (sub *CurrencySubscription) percentLevel(percent *float64) *float64 {
+	if percent == nil || sub.ReferenceRate <= 0 {
+		return nil
 	}
-	client := &http.Client{}
-	res, httpErr := client.Do(req)
-	if httpErr != nil {
-		return httpErr
+	level := sub.ReferenceRate * (1 + *percent/100)
+	return &level
+}
+
+// IntervalStep returns the rate change the alert fires after, resolving a
+// percentage interval against LastNotifiedRate.
+func (sub *CurrencySubscription) IntervalStep() *float64 {
+	if sub.Interval != nil {
+		return sub.Interval
 	}
-	body, _ := io.ReadAll(res.Body)
-	defer res.Body.Close()
-	if res.StatusCode != 204 && res.StatusCode != 200 {
-		return fmt.Errorf("error deleting subscription: %v", string(body))
+	if sub.IntervalPercent == nil || sub.LastNotifiedRate <= 0 {
+		return nil
 	}
-	return nil
+	step := sub.LastNotifiedRate * *sub.IntervalPercent / 100
+	return &step
 }
 
-func GetCurrencySubscription(chatID int64, currency string) (*CurrencySubscription, error) {
-	endpoint := fmt.Sprintf("%v/items/notifybot_currency_subscriptions", utils.DirectusHost)
-	reqBody := []byte(fmt.Sprintf(`{
-		"query": {
-			"filter": {
-				"_and": [
-					{"chat_id": {"_eq": "%v"}},
-					{"currency": {"_eq": "%v"}}
-				]
-			}
-		}
-	}`, chatID, currency))
-	req, httpErr := http.NewRequest("SEARCH", endpoint, bytes.NewBuffer(reqBody))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", utils.DirectusToken))
-	if httpErr != nil {
-		return nil, httpErr
-	}
-	client := &http.Client{}
-	res, httpErr := client.Do(req)
-	if httpErr != nil {
-		return nil, httpErr
-	}
-	defer res.Body.Close()
-	body, _ := io.ReadAll(res.Body)
-	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("error getting subscription: %v", string(body))
-	}
-	var response map[string][]CurrencySubscription
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, err
-	}
-	if len(response["data"]) == 0 {
-		return nil, nil
-	}
-	return &response["data"][0], nil
+func (sub *CurrencySubscription) hysteresisBand(level float64) float64 {
+	switch {
+	case sub.Hysteresis != nil:
+		return *sub.Hysteresis
+	case sub.HysteresisPercent != nil:
+		return level * *sub.HysteresisPercent / 100
+	}
+	return 0
 }
 
-func GetCurrencySubscriptionsByChatID(chatID int64) ([]CurrencySubscription, error) {
-	endpoint := fmt.Sprintf("%v/items/notifybot_currency_subscriptions", utils.DirectusHost)
-	reqBody := []byte(fmt.Sprintf(`{
-		"query": {
-			"filter": {
-				"chat_id": {"_eq": "%v"}
-			}
+// RearmLevel returns the rate a repeating threshold has to move back past
+// after firing before it can fire again.
+func (sub *CurrencySubscription) RearmLevel(trigger string) *float64 {
+	var rearm float64
+	switch trigger {
+	case TriggerAbove:
+		level := sub.AboveLevel()
+		if level == nil {
+			return nil
 		}
-	}`, chatID))
-	req, httpErr := http.NewRequest("SEARCH", endpoint, bytes.NewBuffer(reqBody))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", utils.DirectusToken))
-	if httpErr != nil {
-		return nil, httpErr
-	}
-	client := &http.Client{}
-	res, httpErr := client.Do(req)
-	if httpErr != nil {
-		return nil, httpErr
-	}
-	defer res.Body.Close()
-	body, _ := io.ReadAll(res.Body)
-	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("error getting subscriptions: %v", string(body))
-	}
-	var response map[string][]CurrencySubscription
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, err
-	}
-	return response["data"], nil
-}
-
-func GetAllActiveSubscriptions() ([]CurrencySubscription, error) {
-	endpoint := fmt.Sprintf("%v/items/notifybot_currency_subscriptions", utils.DirectusHost)
-	reqBody := []byte(`{
-		"query": {
-			"filter": {
-				"enabled": {"_eq": true}
-			}
+		rearm = *level - sub.hysteresisBand(*level)
+	case TriggerBelow:
+		level := sub.BelowLevel()
+		if level == nil {
+			return nil
 		}
-	}`)
-	req, httpErr := http.NewRequest("SEARCH", endpoint, bytes.NewBuffer(reqBody))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", utils.DirectusToken))
-	if httpErr != nil {
-		return nil, httpErr
-	}
-	client := &http.Client{}
-	res, httpErr := client.Do(req)
-	if httpErr != nil {
-		return nil, httpErr
-	}
-	defer res.Body.Close()
-	body, _ := io.ReadAll(res.Body)
-	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("error getting subscriptions: %v", string(body))
-	}
-	var response map[string][]CurrencySubscription
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, err
-	}
-	return response["data"], nil
+		rearm = *level + sub.hysteresisBand(*level)
+	default:
+		return nil
+	}
+	return &rearm
 }
 
-func CreateOrUpdateSubscription(chatID int64, currency string, thresholdAbove, thresholdBelow, interval *float64) (*CurrencySubscription, error) {
-	existing, err := GetCurrencySubscription(chatID, currency)
-	if err != nil {
-		return nil, err
+// Rearm clears Triggered once the rate is back past the hysteresis band of the
+// threshold that fired. It reports whether the alert changed.
+func (sub *CurrencySubscription) Rearm(currentRate float64) bool {
+	if sub.Triggered == "" {
+		return false
 	}
-
-	if existing != nil {
-		if thresholdAbove != nil {
-			existing.ThresholdAbove = thresholdAbove
-		}
-		if thresholdBelow != nil {
-			existing.ThresholdBelow = thresholdBelow
-		}
-		if interval != nil {
-			existing.Interval = interval
-		}
-		if err := existing.Update(); err != nil {
-			return nil, err
-		}
-		return existing, nil
+	level := sub.RearmLevel(sub.Triggered)
+	if level != nil &&
+		(sub.Triggered != TriggerAbove || currentRate > *level) &&
+		(sub.Triggered != TriggerBelow || currentRate < *level) {
+		return false
 	}
+	sub.Triggered = ""
+	return true
+}
 
-	sub := &CurrencySubscription{
-		ChatID:           chatID,
-		Currency:         currency,
-		ThresholdAbove:   thresholdAbove,
-		ThresholdBelow:   thresholdBelow,
-		Interval:         interval,
-		LastNotifiedRate: 0,
-		Enabled:          true,
+func (sub *CurrencySubscription) Describe() string {
+	base := sub.Base()
+	var conditions []string
+	if level := sub.AboveLevel(); level != nil {
+		conditions = append(conditions, fmt.Sprintf("above %.4f %s%s", *level, base, formatPercent(sub.ThresholdAbovePercent)))
+	}
+	if level := sub.BelowLevel(); level != nil {
+		conditions = append(conditions, fmt.Sprintf("below %.4f %s%s", *level, base, formatPercent(sub.ThresholdBelowPercent)))
+	}
+	if sub.IntervalPercent != nil {
+		conditions = append(conditions, fmt.Sprintf("every %.2f%% change", *sub.IntervalPercent))
+	} else if sub.Interval != nil {
+		conditions = append(conditions, fmt.Sprintf("every %.4f %s change", *sub.Interval, base))
+	}
+	if len(sub.Ladder) > 0 {
+		conditions = append(conditions, fmt.Sprintf("ladder %s %s (%d rungs)", sub.LadderDirection, formatRungs(sub.Ladder, base), len(sub.Ladder)))
+	}
+	if sub.Indicator != nil {
+		conditions = append(conditions, "when "+sub.Indicator.Describe())
 	}
-	if err := sub.Create(); err != nil {
-		return nil, err
+	if sub.Repeat && len(conditions) > 0 {
+		conditions = append(conditions, "repeating"+sub.describeHysteresis())
 	}
-	return sub, nil
+	if len(conditions) == 0 {
+		return sub.Pair()
+	}
+	return sub.Pair() + " " + strings.Join(conditions, ", ")
 }
 
-func (sub *CurrencySubscription) ShouldNotifyForThreshold(currentRate float64) bool {
-	if sub.ThresholdAbove != nil && currentRate >= *sub.ThresholdAbove {
-		return true
+func (sub *CurrencySubscription) describeHysteresis() string {
+	switch {
+	case sub.Hysteresis != nil:
+		return fmt.Sprintf(" (band %.4f %s)", *sub.Hysteresis, sub.Base())
+	case sub.HysteresisPercent != nil:
+		return fmt.Sprintf(" (band %.2f%%)", *sub.HysteresisPercent)
+	}
+	return ""
+}
+
+// formatRungs describes a ladder by its first and last rung.
+func formatRungs(rungs []float64, base string) string {
+	if len(rungs) == 1 {
+		return fmt.Sprintf("%.4f %s", rungs[0], base)
 	}
-	if sub.ThresholdBelow != nil && currentRate <= *sub.ThresholdBelow {
-		return true
+	return fmt.Sprintf("%.4f → %.4f %s", rungs[0], rungs[len(rungs)-1], base)
+}
+
+// formatPercent renders a percentage threshold as a suffix for its resolved
+// level, e.g. " (+2.00%)".
+func formatPercent(percent *float64) string {
+	if percent == nil {
+		return ""
 	}
-	return false
+	return fmt.Sprintf(" (%+.2f%%)", *percent)
+}
+
+func (sub *CurrencySubscription) ShouldNotifyForThreshold(currentRate float64) bool {
+	trigger := sub.Trigger(currentRate)
+	return trigger == TriggerAbove || trigger == TriggerBelow
 }
 
 func (sub *CurrencySubscription) ShouldNotifyForInterval(currentRate float64) bool {
-	if sub.Interval == nil || sub.LastNotifiedRate == 0 {
+	step := sub.IntervalStep()
+	if step == nil || sub.LastNotifiedRate == 0 {
 		return false
 	}
 	diff := currentRate - sub.LastNotifiedRate
 	if diff < 0 {
 		diff = -diff
 	}
-	return diff >= *sub.Interval
+	return diff >= *step
+}
+
+// Trigger returns which of the alert's conditions currentRate meets, or an
+// empty string if none do.
+func (sub *CurrencySubscription) Trigger(currentRate float64) string {
+	above, below := sub.AboveLevel(), sub.BelowLevel()
+	switch {
+	case above != nil && currentRate >= *above && sub.Triggered != TriggerAbove:
+		return TriggerAbove
+	case below != nil && currentRate <= *below && sub.Triggered != TriggerBelow:
+		return TriggerBelow
+	case len(sub.CrossedRungs(currentRate)) > 0:
+		return TriggerLadder
+	case sub.ShouldNotifyForInterval(currentRate):
+		return TriggerInterval
+	}
+	return ""
+}
+
+// MarkNotified records that the alert was sent at currentRate. One-shot
+// threshold alerts fire once, so a threshold trigger clears them and disables
+// the alert if nothing is left to watch. Repeating thresholds are kept and wait
+// to re-arm instead. A ladder trigger removes the rungs that were crossed.
+func (sub *CurrencySubscription) MarkNotified(currentRate float64, trigger string, at time.Time) {
+	sub.LastNotifiedRate = currentRate
+	sub.LastNotificationTime = at
+	switch {
+	case sub.Repeat && (trigger == TriggerAbove || trigger == TriggerBelow):
+		sub.Triggered = trigger
+		return
+	case trigger == TriggerAbove || trigger == TriggerBelow:
+		sub.ThresholdAbove, sub.ThresholdAbovePercent = nil, nil
+		sub.ThresholdBelow, sub.ThresholdBelowPercent = nil, nil
+	case trigger == TriggerLadder:
+		sub.DropCrossedRungs(currentRate)
+	default:
+		return
+	}
+	if !sub.hasConditions() {
+		sub.Enabled = false
+	}
 }
 
 func (sub *CurrencySubscription) GetNotificationMessage(currentRate float64, rates []HistoricalRate) string {
+	base := sub.Base()
+
 	var thresholdMsg string
-	if sub.ThresholdAbove != nil && currentRate >= *sub.ThresholdAbove {
-		thresholdMsg = fmt.Sprintf("📊 Threshold: Above %.4f SGD ✓ triggered\n", *sub.ThresholdAbove)
-	} else if sub.ThresholdBelow != nil && currentRate <= *sub.ThresholdBelow {
-		thresholdMsg = fmt.Sprintf("📊 Threshold: Below %.4f SGD ✓ triggered\n", *sub.ThresholdBelow)
+	trigger := sub.Trigger(currentRate)
+	switch trigger {
+	case TriggerAbove:
+		thresholdMsg = fmt.Sprintf("📊 Threshold: Above %.4f %s%s ✓ triggered\n", *sub.AboveLevel(), base, formatPercent(sub.ThresholdAbovePercent))
+	case TriggerBelow:
+		thresholdMsg = fmt.Sprintf("📊 Threshold: Below %.4f %s%s ✓ triggered\n", *sub.BelowLevel(), base, formatPercent(sub.ThresholdBelowPercent))
+	}
+	if rearm := sub.RearmLevel(trigger); sub.Repeat && rearm != nil {
+		thresholdMsg += fmt.Sprintf("🔁 Repeats once the rate moves back past %.4f %s\n", *rearm, base)
+	}
+	if trigger == TriggerLadder {
+		crossed := sub.CrossedRungs(currentRate)
+		levels := make([]string, len(crossed))
+		for i, rung := range crossed {
+			levels[i] = fmt.Sprintf("%.4f", rung)
+		}
+		remaining := len(sub.Ladder) - len(crossed)
+		thresholdMsg = fmt.Sprintf("🪜 Ladder: crossed %s %s, ", strings.Join(levels, ", "), base)
+		if remaining == 0 {
+			thresholdMsg += "that was the last rung\n"
+		} else {
+			thresholdMsg += fmt.Sprintf("%d remaining (next %.4f %s)\n", remaining, sub.Ladder[len(crossed)], base)
+		}
 	}
 
 	var changeMsg string
@@ -312,7 +412,7 @@ func (sub *CurrencySubscription) GetNotificationMessage(currentRate float64, rat
 		if change < 0 {
 			changeSymbol = ""
 		}
-		changeMsg = fmt.Sprintf("Change from last: %s%.4f SGD\n", changeSymbol, change)
+		changeMsg = fmt.Sprintf("Change from last: %s%.4f %s\n", changeSymbol, change, base)
 	}
 
 	var minRate, maxRate float64
@@ -329,12 +429,12 @@ func (sub *CurrencySubscription) GetNotificationMessage(currentRate float64, rat
 	}
 
 	return fmt.Sprintf(
-		"💱 *%s/SGD Rate Alert*\n\n"+
-			"1 %s → %.4f SGD\n"+
-			"1 SGD → %.4f %s\n\n"+
+		"💱 *%s/%s Rate Alert*\n\n"+
+			"1 %s → %.4f %s\n"+
+			"1 %s → %.4f %s\n\n"+
 			"%s"+
 			"%s"+
 			"📈 12-Month Range: %.4f - %.4f\n",
-		sub.Currency, sub.Currency, currentRate, 1/currentRate, sub.Currency, changeMsg, thresholdMsg, minRate, maxRate,
+		sub.Currency, base, sub.Currency, currentRate, base, base, 1/currentRate, sub.Currency, changeMsg, thresholdMsg, minRate, maxRate,
 	)
 }
@@ -0,0 +1,153 @@
+package schemas
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencySubscription_BaseDefaultsToSGD(t *testing.T) {
+	var sub CurrencySubscription
+	require.NoError(t, json.Unmarshal([]byte(`{"chat_id": "123", "currency": "USD"}`), &sub))
+
+	assert.Equal(t, "SGD", sub.Base())
+	assert.Equal(t, "USD/SGD", sub.Pair())
+}
+
+func TestCurrencySubscription_NotificationMessageUsesBase(t *testing.T) {
+	above := 4.45
+	sub := CurrencySubscription{
+		ChatID:           123,
+		BaseCurrency:     "MYR",
+		Currency:         "USD",
+		ThresholdAbove:   &above,
+		LastNotifiedRate: 4.40,
+	}
+
+	msg := sub.GetNotificationMessage(4.47, []HistoricalRate{{Rate: 4.30}, {Rate: 4.60}})
+
+	assert.Contains(t, msg, "USD/MYR Rate Alert")
+	assert.Contains(t, msg, "1 USD → 4.4700 MYR")
+	assert.Contains(t, msg, "Above 4.4500 MYR")
+	assert.Contains(t, msg, "+0.0700 MYR")
+	assert.NotContains(t, msg, "SGD")
+}
+
+func TestCurrencySubscription_TriggerAndMarkNotified(t *testing.T) {
+	above, interval := 1.40, 0.05
+	sub := CurrencySubscription{Currency: "USD", ThresholdAbove: &above, Interval: &interval, LastNotifiedRate: 1.30, Enabled: true}
+
+	assert.Equal(t, "", sub.Trigger(1.33))
+	assert.Equal(t, TriggerInterval, sub.Trigger(1.36))
+	assert.Equal(t, TriggerAbove, sub.Trigger(1.41))
+
+	at := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+	sub.MarkNotified(1.41, TriggerAbove, at)
+	assert.Nil(t, sub.ThresholdAbove)
+	assert.True(t, sub.Enabled)
+	assert.Equal(t, 1.41, sub.LastNotifiedRate)
+	assert.Equal(t, at, sub.LastNotificationTime)
+
+	below := 1.30
+	sub = CurrencySubscription{Currency: "USD", ThresholdBelow: &below, Enabled: true}
+	sub.MarkNotified(1.29, sub.Trigger(1.29), at)
+	assert.False(t, sub.Enabled)
+}
+
+func TestCurrencySubscription_Percentages(t *testing.T) {
+	abovePercent, belowPercent, intervalPercent := 2.0, -3.0, 1.0
+	sub := CurrencySubscription{Currency: "USD", ThresholdAbovePercent: &abovePercent, ThresholdBelowPercent: &belowPercent, ReferenceRate: 1.35}
+
+	assert.InDelta(t, 1.377, *sub.AboveLevel(), 1e-9)
+	assert.InDelta(t, 1.3095, *sub.BelowLevel(), 1e-9)
+	assert.False(t, sub.ShouldNotifyForThreshold(1.37))
+	assert.True(t, sub.ShouldNotifyForThreshold(1.38))
+	assert.True(t, sub.ShouldNotifyForThreshold(1.30))
+	assert.Contains(t, sub.GetNotificationMessage(1.38, nil), "Above 1.3770 SGD (+2.00%)")
+
+	sub = CurrencySubscription{Currency: "JPY", IntervalPercent: &intervalPercent, LastNotifiedRate: 0.009}
+	assert.False(t, sub.ShouldNotifyForInterval(0.00908))
+	assert.True(t, sub.ShouldNotifyForInterval(0.0089))
+	assert.Equal(t, "JPY/SGD every 1.00% change", sub.Describe())
+}
+
+func TestCurrencySubscription_RepeatWithHysteresis(t *testing.T) {
+	above, band := 1.40, 0.01
+	sub := CurrencySubscription{Currency: "USD", ThresholdAbove: &above, Repeat: true, Hysteresis: &band, Enabled: true}
+	at := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+
+	require.Equal(t, TriggerAbove, sub.Trigger(1.41))
+	assert.Contains(t, sub.GetNotificationMessage(1.41, nil), "Repeats once the rate moves back past 1.3900 SGD")
+	sub.MarkNotified(1.41, TriggerAbove, at)
+	assert.True(t, sub.Enabled)
+	assert.Equal(t, above, *sub.ThresholdAbove)
+	assert.Equal(t, TriggerAbove, sub.Triggered)
+
+	assert.False(t, sub.Rearm(1.395))
+	assert.Equal(t, "", sub.Trigger(1.405))
+	assert.True(t, sub.Rearm(1.389))
+	assert.Equal(t, TriggerAbove, sub.Trigger(1.40))
+	assert.Equal(t, "USD/SGD above 1.4000 SGD, repeating (band 0.0100 SGD)", sub.Describe())
+}
+
+func TestCurrencySubscription_Ladder(t *testing.T) {
+	sub := CurrencySubscription{Currency: "USD", Ladder: []float64{1.34, 1.345, 1.35}, LadderDirection: TriggerAbove, Enabled: true}
+	at := time.Date(2026, 2, 20, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "", sub.Trigger(1.339))
+	require.Equal(t, TriggerLadder, sub.Trigger(1.346))
+	assert.Contains(t, sub.GetNotificationMessage(1.346, nil), "Ladder: crossed 1.3400, 1.3450 SGD, 1 remaining (next 1.3500 SGD)")
+
+	original := sub
+	sub.MarkNotified(1.346, TriggerLadder, at)
+	assert.Equal(t, []float64{1.35}, sub.Ladder)
+	assert.Len(t, original.Ladder, 3)
+	assert.True(t, sub.Enabled)
+
+	assert.Contains(t, sub.GetNotificationMessage(1.36, nil), "that was the last rung")
+	sub.MarkNotified(1.36, TriggerLadder, at)
+	assert.Empty(t, sub.Ladder)
+	assert.False(t, sub.Enabled)
+
+	sub = CurrencySubscription{Currency: "USD", Ladder: []float64{1.36, 1.355}, LadderDirection: TriggerBelow}
+	assert.Equal(t, "", sub.Trigger(1.361))
+	assert.Equal(t, []float64{1.36}, sub.CrossedRungs(1.358))
+	assert.Equal(t, "USD/SGD ladder below 1.3600 → 1.3550 SGD (2 rungs)", sub.Describe())
+}
+
+func TestIndicatorAlert_Describe(t *testing.T) {
+	tests := []struct {
+		alert    IndicatorAlert
+		describe string
+		args     string
+	}{
+		{IndicatorAlert{Kind: IndicatorSMA, Fast: 20, Slow: 50, Direction: TriggerAbove}, "20-day SMA crosses above 50-day SMA", "sma 20 50 above"},
+		{IndicatorAlert{Kind: IndicatorRSI, Period: 14, Level: 30, Direction: TriggerBelow}, "14-day RSI crosses below 30", "rsi 14 below 30"},
+		{IndicatorAlert{Kind: IndicatorBollinger, Period: 20, Deviations: 2}, "rate leaves either 2-sigma 20-day Bollinger band", "bollinger 20 2"},
+		{IndicatorAlert{Kind: IndicatorBollinger, Period: 20, Deviations: 2.5, Direction: TriggerAbove}, "rate leaves the upper 2.5-sigma 20-day Bollinger band", "bollinger 20 2.5 above"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.describe, tt.alert.Describe())
+		assert.Equal(t, tt.args, tt.alert.Args())
+	}
+
+	sub := CurrencySubscription{Currency: "USD", Indicator: &IndicatorAlert{Kind: IndicatorEMA, Fast: 12, Slow: 26, Direction: TriggerBelow}}
+	assert.Equal(t, "USD/SGD when 12-day EMA crosses below 26-day EMA", sub.Describe())
+	fired := sub
+	fired.Indicator = &IndicatorAlert{Kind: IndicatorEMA, Fast: 12, Slow: 26, Direction: TriggerBelow, LastSignal: "2026-02-20"}
+	assert.True(t, sub.SameAlert(&fired))
+	assert.Equal(t, "", sub.Trigger(1.35), "indicators need the rate history")
+}
+
+func TestChatSettings_BaseCurrency(t *testing.T) {
+	var cs ChatSettings
+	require.NoError(t, json.Unmarshal([]byte(`{"chat_id": "123", "created_at": "2026-02-20T10:00:00"}`), &cs))
+	assert.Equal(t, "SGD", cs.Base())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"chat_id": "123", "base_currency": "HKD", "created_at": "2026-02-20T10:00:00"}`), &cs))
+	assert.Equal(t, "HKD", cs.Base())
+	assert.Equal(t, int64(123), cs.ChatId)
+}
@@ -6,7 +6,10 @@ import (
 	"io"
 	"net/http"
 	"sort"
+	"strings"
 	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/httpclient"
 )
 
 const FrankfurterAPIURL = "https://api.frankfurter.dev/v1"
@@ -31,99 +34,140 @@ type HistoricalRate struct {
 	Rate float64
 }
 
-func FetchLatestExchangeRate(currency string) (float64, *FrankfurterLatestResponse, error) {
-	endpoint := fmt.Sprintf("%s/latest?from=SGD&to=%s", FrankfurterAPIURL, currency)
+type FrankfurterProvider struct {
+	BaseURL string
+	Client  *httpclient.Client
+}
+
+func NewFrankfurterProvider(baseURL string) *FrankfurterProvider {
+	if baseURL == "" {
+		baseURL = FrankfurterAPIURL
+	}
+	return &FrankfurterProvider{
+		BaseURL: baseURL,
+		Client:  httpclient.Default,
+	}
+}
 
+func (p *FrankfurterProvider) Name() string {
+	return "Frankfurter"
+}
+
+func (p *FrankfurterProvider) NextPublication(after time.Time) time.Time {
+	return nextBusinessDayAt(after, loadLocationOrFixed("Europe/Berlin", 1), 16, 30, IsTARGETHoliday)
+}
+
+func (p *FrankfurterProvider) get(endpoint string, out interface{}) error {
 	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
 	if err != nil {
-		return 0, nil, err
+		return err
 	}
 	req.Header.Set("User-Agent", "Telegram-NotifyBot/1.0")
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	res, err := client.Do(req)
+	res, err := p.Client.Do(req)
 	if err != nil {
-		return 0, nil, err
+		return err
 	}
 	defer res.Body.Close()
 
 	body, _ := io.ReadAll(res.Body)
 	if res.StatusCode != 200 {
-		return 0, nil, fmt.Errorf("Frankfurter API error: status %d, body: %s", res.StatusCode, string(body))
-	}
-
-	var response FrankfurterLatestResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return 0, nil, err
-	}
-
-	rate, ok := response.Rates[currency]
-	if !ok {
-		return 0, nil, fmt.Errorf("rate not available for currency: %s", currency)
+		return fmt.Errorf("Frankfurter API error: status %d, body: %s", res.StatusCode, string(body))
 	}
 
-	return 1.0 / rate, &response, nil
+	return json.Unmarshal(body, out)
 }
 
-func FetchHistoricalExchangeRates(currency string, days int) ([]HistoricalRate, error) {
-	if days <= 0 {
-		days = 365
-	}
-	if days > 3650 {
-		days = 3650
+func (p *FrankfurterProvider) LatestRates(base string, currencies []string) (map[string]*ExchangeRate, error) {
+	quotes := make(map[string]*ExchangeRate)
+	currencies = QuoteCurrencies(base, currencies)
+	if len(currencies) == 0 {
+		return quotes, nil
 	}
 
-	endDate := time.Now()
-	startDate := endDate.AddDate(0, 0, -days)
-
-	endpoint := fmt.Sprintf("%s/%s..%s?from=SGD&to=%s",
-		FrankfurterAPIURL,
-		startDate.Format("2006-01-02"),
-		endDate.Format("2006-01-02"),
-		currency)
+	endpoint := fmt.Sprintf("%s/latest?from=%s&to=%s", p.BaseURL, base, strings.Join(currencies, ","))
 
-	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
-	if err != nil {
+	var response FrankfurterLatestResponse
+	if err := p.get(endpoint, &response); err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", "Telegram-NotifyBot/1.0")
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	res, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	for _, currency := range currencies {
+		rate, ok := response.Rates[currency]
+		if !ok || rate == 0 {
+			continue
+		}
+		quotes[currency] = &ExchangeRate{
+			Base:     response.Base,
+			Currency: currency,
+			Rate:     1.0 / rate,
+			Date:     response.Date,
+			Source:   p.Name(),
+		}
 	}
-	defer res.Body.Close()
 
-	body, _ := io.ReadAll(res.Body)
-	if res.StatusCode != 200 {
-		return nil, fmt.Errorf("Frankfurter API error: status %d, body: %s", res.StatusCode, string(body))
+	return quotes, nil
+}
+
+func (p *FrankfurterProvider) HistoricalRates(base string, currencies []string, start, end time.Time) (map[string][]HistoricalRate, error) {
+	histories := make(map[string][]HistoricalRate)
+	currencies = QuoteCurrencies(base, currencies)
+	if len(currencies) == 0 {
+		return histories, nil
 	}
 
+	endpoint := fmt.Sprintf("%s/%s..%s?from=%s&to=%s",
+		p.BaseURL,
+		start.Format("2006-01-02"),
+		end.Format("2006-01-02"),
+		base,
+		strings.Join(currencies, ","))
+
 	var response FrankfurterHistoricalResponse
-	if err := json.Unmarshal(body, &response); err != nil {
+	if err := p.get(endpoint, &response); err != nil {
 		return nil, err
 	}
 
-	rates := make([]HistoricalRate, 0, len(response.Rates))
 	for dateStr, rateMap := range response.Rates {
-		rate, ok := rateMap[currency]
-		if !ok {
-			continue
-		}
 		date, err := time.Parse("2006-01-02", dateStr)
 		if err != nil {
 			continue
 		}
-		rates = append(rates, HistoricalRate{
-			Date: date,
-			Rate: 1.0 / rate,
+		for _, currency := range currencies {
+			rate, ok := rateMap[currency]
+			if !ok || rate == 0 {
+				continue
+			}
+			histories[currency] = append(histories[currency], HistoricalRate{
+				Date: date,
+				Rate: 1.0 / rate,
+			})
+		}
+	}
+
+	for _, rates := range histories {
+		sort.Slice(rates, func(i, j int) bool {
+			return rates[i].Date.Before(rates[j].Date)
 		})
 	}
 
-	sort.Slice(rates, func(i, j int) bool {
-		return rates[i].Date.Before(rates[j].Date)
+	return histories, nil
+}
+
+func (p *FrankfurterProvider) SupportedCurrencies() ([]CurrencyInfo, error) {
+	var response map[string]string
+	if err := p.get(fmt.Sprintf("%s/currencies", p.BaseURL), &response); err != nil {
+		return nil, err
+	}
+
+	currencies := make([]CurrencyInfo, 0, len(response))
+	for code, name := range response {
+		currencies = append(currencies, CurrencyInfo{Code: code, Name: name})
+	}
+
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].Code < currencies[j].Code
 	})
 
-	return rates, nil
+	return currencies, nil
 }
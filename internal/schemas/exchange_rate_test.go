@@ -204,3 +204,125 @@ func TestFetchHistoricalExchangeRates_DataSortedChronologically(t *testing.T) {
 			"rates should be sorted chronologically, got %v before %v", rates[i].Date, rates[i-1].Date)
 	}
 }
+
+func TestFrankfurterProvider_LatestRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/latest", r.URL.Path)
+		assert.Equal(t, "SGD", r.URL.Query().Get("from"))
+		assert.Equal(t, "USD", r.URL.Query().Get("to"))
+
+		response := FrankfurterLatestResponse{
+			Amount: 1.0,
+			Base:   "SGD",
+			Date:   "2026-02-20",
+			Rates:  map[string]float64{"USD": 1.2678},
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	provider := NewFrankfurterProvider(server.URL)
+	quote, err := FetchLatestRate(provider, "SGD", "USD")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.7889, quote.Rate, 0.001)
+	assert.Equal(t, "2026-02-20", quote.Date)
+	assert.Equal(t, "Frankfurter", quote.Source)
+}
+
+func TestFrankfurterProvider_HistoricalRates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/2026-01-01..2026-02-20", r.URL.Path)
+
+		response := FrankfurterHistoricalResponse{
+			Amount:    1.0,
+			Base:      "SGD",
+			StartDate: "2026-01-01",
+			EndDate:   "2026-02-20",
+			Rates: map[string]map[string]float64{
+				"2026-02-20": {"USD": 1.2678},
+				"2026-01-15": {"USD": 1.3500},
+			},
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	provider := NewFrankfurterProvider(server.URL)
+	rates, err := FetchHistoricalRates(provider, "SGD", "USD",
+		time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), rates[0].Date)
+}
+
+func TestFrankfurterProvider_LatestRates_SingleRequest(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "USD,EUR,JPY", r.URL.Query().Get("to"))
+
+		response := FrankfurterLatestResponse{
+			Amount: 1.0,
+			Base:   "SGD",
+			Date:   "2026-02-20",
+			Rates:  map[string]float64{"USD": 0.7473, "EUR": 0.7131},
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	provider := NewFrankfurterProvider(server.URL)
+	quotes, err := provider.LatestRates("SGD", []string{"USD", "SGD", "EUR", "USD", "JPY"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+	require.Len(t, quotes, 2)
+	assert.InDelta(t, 1.3381, quotes["USD"].Rate, 0.001)
+	assert.InDelta(t, 1.4023, quotes["EUR"].Rate, 0.001)
+	assert.NotContains(t, quotes, "JPY")
+}
+
+func TestFrankfurterProvider_HistoricalRates_Batch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "USD,EUR", r.URL.Query().Get("to"))
+
+		response := FrankfurterHistoricalResponse{
+			Amount: 1.0,
+			Base:   "SGD",
+			Rates: map[string]map[string]float64{
+				"2026-02-20": {"USD": 0.7473, "EUR": 0.7131},
+				"2026-02-19": {"USD": 0.7480},
+			},
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	provider := NewFrankfurterProvider(server.URL)
+	histories, err := provider.HistoricalRates("SGD", []string{"USD", "EUR"},
+		time.Date(2026, 2, 19, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, histories["USD"], 2)
+	require.Len(t, histories["EUR"], 1)
+	assert.Equal(t, time.Date(2026, 2, 19, 0, 0, 0, 0, time.UTC), histories["USD"][0].Date)
+}
+
+func TestFrankfurterProvider_SupportedCurrencies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/currencies", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]string{
+			"USD": "United States Dollar",
+			"EUR": "Euro",
+		})
+	}))
+	defer server.Close()
+
+	provider := NewFrankfurterProvider(server.URL)
+	currencies, err := provider.SupportedCurrencies()
+	require.NoError(t, err)
+	assert.Equal(t, []CurrencyInfo{
+		{Code: "EUR", Name: "Euro"},
+		{Code: "USD", Name: "United States Dollar"},
+	}, currencies)
+}
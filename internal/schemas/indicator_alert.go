@@ -0,0 +1,77 @@
+package schemas
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	IndicatorSMA       = "sma"
+	IndicatorEMA       = "ema"
+	IndicatorRSI       = "rsi"
+	IndicatorBollinger = "bollinger"
+)
+
+// IndicatorAlert fires when a technical indicator computed over the daily rate
+// history gives a signal on the latest data date:
+//
+//   - sma and ema: the Fast-period average crosses the Slow-period average in
+//     Direction.
+//   - rsi: the Period RSI crosses Level in Direction.
+//   - bollinger: the rate leaves the Period-day band Deviations standard
+//     deviations wide, through the upper band for "above", the lower band for
+//     "below", or either when Direction is empty.
+//
+// LastSignal is the data date of the last signal sent, so the same signal is
+// not sent twice.
+type IndicatorAlert struct {
+	Kind       string  `json:"kind"`
+	Fast       int     `json:"fast,omitempty"`
+	Slow       int     `json:"slow,omitempty"`
+	Period     int     `json:"period,omitempty"`
+	Deviations float64 `json:"deviations,omitempty"`
+	Level      float64 `json:"level,omitempty"`
+	Direction  string  `json:"direction,omitempty"`
+	LastSignal string  `json:"last_signal,omitempty"`
+}
+
+// SameSignal reports whether a and b describe the same alert, ignoring when
+// they last fired.
+func (a IndicatorAlert) SameSignal(b IndicatorAlert) bool {
+	a.LastSignal, b.LastSignal = "", ""
+	return a == b
+}
+
+func (a IndicatorAlert) Describe() string {
+	switch a.Kind {
+	case IndicatorSMA, IndicatorEMA:
+		name := strings.ToUpper(a.Kind)
+		return fmt.Sprintf("%d-day %s crosses %s %d-day %s", a.Fast, name, a.Direction, a.Slow, name)
+	case IndicatorRSI:
+		return fmt.Sprintf("%d-day RSI crosses %s %s", a.Period, a.Direction, strconv.FormatFloat(a.Level, 'f', -1, 64))
+	case IndicatorBollinger:
+		band := "either"
+		switch a.Direction {
+		case TriggerAbove:
+			band = "the upper"
+		case TriggerBelow:
+			band = "the lower"
+		}
+		return fmt.Sprintf("rate leaves %s %s-sigma %d-day Bollinger band", band, strconv.FormatFloat(a.Deviations, 'f', -1, 64), a.Period)
+	}
+	return a.Kind
+}
+
+// Args returns the alert in the form /fx_indicator accepts after the pair.
+func (a IndicatorAlert) Args() string {
+	switch a.Kind {
+	case IndicatorSMA, IndicatorEMA:
+		return fmt.Sprintf("%s %d %d %s", a.Kind, a.Fast, a.Slow, a.Direction)
+	case IndicatorRSI:
+		return fmt.Sprintf("%s %d %s %s", a.Kind, a.Period, a.Direction, strconv.FormatFloat(a.Level, 'f', -1, 64))
+	case IndicatorBollinger:
+		return strings.TrimSpace(fmt.Sprintf("%s %d %s %s", a.Kind, a.Period, strconv.FormatFloat(a.Deviations, 'f', -1, 64), a.Direction))
+	}
+	return a.Kind
+}
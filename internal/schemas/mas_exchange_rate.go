@@ -0,0 +1,285 @@
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/httpclient"
+)
+
+const (
+	MASAPIURL          = "https://eservices.mas.gov.sg/api/action/datastore/search.json"
+	MASDailyResourceID = "95932927-c8bc-4e7a-b484-68a66a24edfe"
+	masPageSize        = 1000
+)
+
+var masCurrencyNames = map[string]string{
+	"AED": "UAE Dirham",
+	"AUD": "Australian Dollar",
+	"CAD": "Canadian Dollar",
+	"CHF": "Swiss Franc",
+	"CNY": "Chinese Renminbi",
+	"EUR": "Euro",
+	"GBP": "British Pound",
+	"HKD": "Hong Kong Dollar",
+	"IDR": "Indonesian Rupiah",
+	"INR": "Indian Rupee",
+	"JPY": "Japanese Yen",
+	"KRW": "Korean Won",
+	"MYR": "Malaysian Ringgit",
+	"NZD": "New Zealand Dollar",
+	"PHP": "Philippine Peso",
+	"QAR": "Qatar Riyal",
+	"SAR": "Saudi Arabia Riyal",
+	"THB": "Thai Baht",
+	"TWD": "New Taiwan Dollar",
+	"USD": "US Dollar",
+	"VND": "Vietnamese Dong",
+}
+
+type MASRecord map[string]interface{}
+
+type MASSearchResponse struct {
+	Success bool `json:"success"`
+	Result  struct {
+		Total   json.Number `json:"total"`
+		Limit   int         `json:"limit"`
+		Records []MASRecord `json:"records"`
+	} `json:"result"`
+}
+
+type masQuote struct {
+	Date  time.Time
+	Rates map[string]float64
+}
+
+type MASProvider struct {
+	BaseURL    string
+	ResourceID string
+	DateField  string
+	Client     *httpclient.Client
+}
+
+func NewMASProvider(baseURL, resourceID string) *MASProvider {
+	if baseURL == "" {
+		baseURL = MASAPIURL
+	}
+	if resourceID == "" {
+		resourceID = MASDailyResourceID
+	}
+	return &MASProvider{
+		BaseURL:    baseURL,
+		ResourceID: resourceID,
+		DateField:  "end_of_day",
+		Client:     httpclient.Default,
+	}
+}
+
+func (p *MASProvider) Name() string {
+	return "MAS"
+}
+
+func (p *MASProvider) NextPublication(after time.Time) time.Time {
+	return nextWeekdayAt(after, loadLocationOrFixed("Asia/Singapore", 8), 12, 0)
+}
+
+// MAS quotes some currencies per 100 units (e.g. jpy_sgd_100), so each rate is
+// divided by its field suffix to get SGD per single unit.
+func (p *MASProvider) parseMASRecord(record MASRecord) (*masQuote, error) {
+	rawDate, ok := record[p.DateField].(string)
+	if !ok {
+		return nil, fmt.Errorf("MAS record missing %s", p.DateField)
+	}
+	date, err := time.Parse("2006-01-02", rawDate)
+	if err != nil {
+		date, err = time.Parse("2006-01", rawDate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	quote := &masQuote{Date: date, Rates: make(map[string]float64)}
+	for key, value := range record {
+		parts := strings.Split(key, "_")
+		if len(parts) < 2 || len(parts) > 3 || parts[1] != "sgd" || len(parts[0]) != 3 {
+			continue
+		}
+		units := 1.0
+		if len(parts) == 3 {
+			units, err = strconv.ParseFloat(parts[2], 64)
+			if err != nil || units <= 0 {
+				continue
+			}
+		}
+		rate, ok := parseMASValue(value)
+		if !ok {
+			continue
+		}
+		quote.Rates[strings.ToUpper(parts[0])] = rate / units
+	}
+	return quote, nil
+}
+
+func parseMASValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case string:
+		rate, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || rate <= 0 {
+			return 0, false
+		}
+		return rate, true
+	case float64:
+		return v, v > 0
+	default:
+		return 0, false
+	}
+}
+
+func (p *MASProvider) search(params url.Values) (*MASSearchResponse, error) {
+	params.Set("resource_id", p.ResourceID)
+	endpoint := fmt.Sprintf("%s?%s", p.BaseURL, params.Encode())
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Telegram-NotifyBot/1.0")
+
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != 200 {
+		return nil, fmt.Errorf("MAS API error: status %d, body: %s", res.StatusCode, string(body))
+	}
+
+	var response MASSearchResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+	if !response.Success {
+		return nil, fmt.Errorf("MAS API error: request was not successful, body: %s", string(body))
+	}
+	return &response, nil
+}
+
+func (p *MASProvider) latestQuote() (*masQuote, error) {
+	params := url.Values{}
+	params.Set("limit", "1")
+	params.Set("sort", fmt.Sprintf("%s desc", p.DateField))
+
+	response, err := p.search(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Result.Records) == 0 {
+		return nil, fmt.Errorf("MAS API returned no records")
+	}
+	return p.parseMASRecord(response.Result.Records[0])
+}
+
+func (p *MASProvider) LatestRates(base string, currencies []string) (map[string]*ExchangeRate, error) {
+	if base != "SGD" {
+		return nil, fmt.Errorf("MAS only quotes rates against SGD, not %s", base)
+	}
+
+	quote, err := p.latestQuote()
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make(map[string]*ExchangeRate)
+	for _, currency := range QuoteCurrencies(base, currencies) {
+		rate, ok := quote.Rates[currency]
+		if !ok {
+			continue
+		}
+		quotes[currency] = &ExchangeRate{
+			Base:     "SGD",
+			Currency: currency,
+			Rate:     rate,
+			Date:     quote.Date.Format("2006-01-02"),
+			Source:   p.Name(),
+		}
+	}
+
+	return quotes, nil
+}
+
+func (p *MASProvider) HistoricalRates(base string, currencies []string, start, end time.Time) (map[string][]HistoricalRate, error) {
+	if base != "SGD" {
+		return nil, fmt.Errorf("MAS only quotes rates against SGD, not %s", base)
+	}
+
+	currencies = QuoteCurrencies(base, currencies)
+	histories := make(map[string][]HistoricalRate)
+
+	for offset := 0; ; offset += masPageSize {
+		params := url.Values{}
+		params.Set("limit", strconv.Itoa(masPageSize))
+		params.Set("offset", strconv.Itoa(offset))
+		params.Set("sort", fmt.Sprintf("%s asc", p.DateField))
+		params.Set(fmt.Sprintf("between[%s]", p.DateField),
+			fmt.Sprintf("%s,%s", start.Format("2006-01-02"), end.Format("2006-01-02")))
+
+		response, err := p.search(params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range response.Result.Records {
+			quote, err := p.parseMASRecord(record)
+			if err != nil {
+				continue
+			}
+			for _, currency := range currencies {
+				rate, ok := quote.Rates[currency]
+				if !ok {
+					continue
+				}
+				histories[currency] = append(histories[currency], HistoricalRate{Date: quote.Date, Rate: rate})
+			}
+		}
+
+		total, _ := response.Result.Total.Int64()
+		if len(response.Result.Records) < masPageSize || int64(offset+masPageSize) >= total {
+			break
+		}
+	}
+
+	for _, rates := range histories {
+		sort.Slice(rates, func(i, j int) bool {
+			return rates[i].Date.Before(rates[j].Date)
+		})
+	}
+
+	return histories, nil
+}
+
+func (p *MASProvider) SupportedCurrencies() ([]CurrencyInfo, error) {
+	quote, err := p.latestQuote()
+	if err != nil {
+		return nil, err
+	}
+
+	currencies := make([]CurrencyInfo, 0, len(quote.Rates))
+	for code := range quote.Rates {
+		currencies = append(currencies, CurrencyInfo{Code: code, Name: masCurrencyNames[code]})
+	}
+
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].Code < currencies[j].Code
+	})
+
+	return currencies, nil
+}
@@ -0,0 +1,141 @@
+package schemas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMASFixtureServer(t *testing.T, fixture string, check func(r *http.Request)) *httptest.Server {
+	body, err := os.ReadFile(filepath.Join("testdata", fixture))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, MASDailyResourceID, r.URL.Query().Get("resource_id"))
+		if check != nil {
+			check(r)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMASProvider_LatestRate(t *testing.T) {
+	server := newMASFixtureServer(t, "mas_daily_latest.json", func(r *http.Request) {
+		assert.Equal(t, "1", r.URL.Query().Get("limit"))
+		assert.Equal(t, "end_of_day desc", r.URL.Query().Get("sort"))
+	})
+
+	provider := NewMASProvider(server.URL, "")
+	quote, err := FetchLatestRate(provider, "SGD", "USD")
+	require.NoError(t, err)
+	assert.InDelta(t, 1.3381, quote.Rate, 1e-9)
+	assert.Equal(t, "SGD", quote.Base)
+	assert.Equal(t, "2026-02-20", quote.Date)
+	assert.Equal(t, "MAS", quote.Source)
+}
+
+func TestMASProvider_LatestRate_Per100Units(t *testing.T) {
+	server := newMASFixtureServer(t, "mas_daily_latest.json", nil)
+	provider := NewMASProvider(server.URL, "")
+
+	tests := []struct {
+		currency string
+		expected float64
+	}{
+		{"JPY", 0.008895},
+		{"KRW", 0.0009312},
+		{"IDR", 0.00008231},
+		{"MYR", 0.3012},
+		{"EUR", 1.4023},
+	}
+
+	for _, tt := range tests {
+		quote, err := FetchLatestRate(provider, "SGD", tt.currency)
+		require.NoError(t, err, tt.currency)
+		assert.InDelta(t, tt.expected, quote.Rate, 1e-12, tt.currency)
+	}
+}
+
+func TestMASProvider_LatestRate_MissingCurrency(t *testing.T) {
+	server := newMASFixtureServer(t, "mas_daily_latest.json", nil)
+	provider := NewMASProvider(server.URL, "")
+
+	_, err := FetchLatestRate(provider, "SGD", "CAD")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not available")
+}
+
+func TestMASProvider_LatestRates(t *testing.T) {
+	requests := 0
+	server := newMASFixtureServer(t, "mas_daily_latest.json", func(r *http.Request) { requests++ })
+	provider := NewMASProvider(server.URL, "")
+
+	quotes, err := provider.LatestRates("SGD", []string{"USD", "JPY", "CAD"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+	assert.Len(t, quotes, 2)
+	assert.InDelta(t, 0.008895, quotes["JPY"].Rate, 1e-12)
+}
+
+func TestMASProvider_HistoricalRates(t *testing.T) {
+	server := newMASFixtureServer(t, "mas_daily_range.json", func(r *http.Request) {
+		assert.Equal(t, "2026-02-01,2026-02-20", r.URL.Query().Get("between[end_of_day]"))
+	})
+	provider := NewMASProvider(server.URL, "")
+
+	start := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC)
+
+	rates, err := FetchHistoricalRates(provider, "SGD", "USD", start, end)
+	require.NoError(t, err)
+	require.Len(t, rates, 3)
+	assert.Equal(t, time.Date(2026, 2, 18, 0, 0, 0, 0, time.UTC), rates[0].Date)
+	assert.InDelta(t, 1.3381, rates[2].Rate, 1e-9)
+
+	rates, err = FetchHistoricalRates(provider, "SGD", "JPY", start, end)
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.InDelta(t, 0.008871, rates[0].Rate, 1e-12)
+}
+
+func TestMASProvider_SupportedCurrencies(t *testing.T) {
+	server := newMASFixtureServer(t, "mas_daily_latest.json", nil)
+	provider := NewMASProvider(server.URL, "")
+
+	currencies, err := provider.SupportedCurrencies()
+	require.NoError(t, err)
+	assert.Len(t, currencies, 14)
+	assert.Contains(t, currencies, CurrencyInfo{Code: "JPY", Name: "Japanese Yen"})
+	assert.NotContains(t, currencies, CurrencyInfo{Code: "CAD", Name: "Canadian Dollar"})
+}
+
+func TestMASProvider_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	provider := NewMASProvider(server.URL, "")
+	provider.Client = httpclient.New(httpclient.Config{})
+	_, err := FetchLatestRate(provider, "SGD", "USD")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "status 503")
+}
+
+func TestMASProvider_RejectsNonSGDBase(t *testing.T) {
+	provider := NewMASProvider("http://127.0.0.1:0", "")
+
+	_, err := FetchLatestRate(provider, "MYR", "USD")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "against SGD")
+}
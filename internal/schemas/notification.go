@@ -0,0 +1,80 @@
+package schemas
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	TriggerAbove     = "above"
+	TriggerBelow     = "below"
+	TriggerInterval  = "interval"
+	TriggerLadder    = "ladder"
+	TriggerIndicator = "indicator"
+)
+
+const (
+	NotificationDelivered = "delivered"
+	NotificationFailed    = "failed"
+)
+
+// Notification records one alert the scheduler tried to deliver, whether or
+// not Telegram accepted it.
+type Notification struct {
+	ID             string    `json:"id,omitempty"`
+	SubscriptionID string    `json:"subscription_id"`
+	ChatID         int64     `json:"chat_id"`
+	BaseCurrency   string    `json:"base_currency"`
+	Currency       string    `json:"currency"`
+	Rate           float64   `json:"rate"`
+	Trigger        string    `json:"trigger"`
+	DataDate       string    `json:"data_date,omitempty"`
+	MessageID      int       `json:"message_id,omitempty"`
+	Status         string    `json:"status"`
+	Error          string    `json:"error,omitempty"`
+	SentAt         time.Time `json:"sent_at"`
+}
+
+func (n Notification) Pair() string {
+	return n.Currency + "/" + n.BaseCurrency
+}
+
+func (n Notification) Delivered() bool {
+	return n.Status == NotificationDelivered
+}
+
+func (n Notification) MarshalJSON() ([]byte, error) {
+	type Alias Notification
+
+	aux := &struct {
+		ChatID string `json:"chat_id"`
+		*Alias
+	}{
+		ChatID: strconv.FormatInt(n.ChatID, 10),
+		Alias:  (*Alias)(&n),
+	}
+	return json.Marshal(aux)
+}
+
+func (n *Notification) UnmarshalJSON(data []byte) error {
+	type Alias Notification
+
+	aux := &struct {
+		ChatID string `json:"chat_id"`
+		*Alias
+	}{
+		Alias: (*Alias)(n),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	chatID, err := strconv.ParseInt(aux.ChatID, 10, 64)
+	if err != nil {
+		return err
+	}
+	n.ChatID = chatID
+	return nil
+}
@@ -0,0 +1,76 @@
+package schemas
+
+import (
+	"time"
+)
+
+type PublicationSchedule interface {
+	NextPublication(after time.Time) time.Time
+}
+
+func loadLocationOrFixed(name string, offsetHours int) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.FixedZone(name, offsetHours*60*60)
+	}
+	return location
+}
+
+func nextWeekdayAt(after time.Time, location *time.Location, hour, minute int) time.Time {
+	return nextBusinessDayAt(after, location, hour, minute, func(time.Time) bool { return false })
+}
+
+func nextBusinessDayAt(after time.Time, location *time.Location, hour, minute int, isHoliday func(time.Time) bool) time.Time {
+	local := after.In(location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, location)
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	for next.Weekday() == time.Saturday || next.Weekday() == time.Sunday || isHoliday(next) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// easterSunday uses the anonymous Gregorian algorithm (Meeus/Jones/Butcher).
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// IsTARGETHoliday reports whether the ECB publishes no reference rates on the
+// given calendar day, besides weekends.
+func IsTARGETHoliday(date time.Time) bool {
+	month, day := date.Month(), date.Day()
+	switch {
+	case month == time.January && day == 1,
+		month == time.May && day == 1,
+		month == time.December && (day == 25 || day == 26):
+		return true
+	}
+
+	easter := easterSunday(date.Year())
+	yearDay := date.YearDay()
+	return yearDay == easter.AddDate(0, 0, -2).YearDay() || yearDay == easter.AddDate(0, 0, 1).YearDay()
+}
+
+// NextPublicationOf returns when provider is next expected to publish, or false
+// when it does not expose a schedule.
+func NextPublicationOf(provider RateProvider, after time.Time) (time.Time, bool) {
+	schedule, ok := provider.(PublicationSchedule)
+	if !ok {
+		return time.Time{}, false
+	}
+	next := schedule.NextPublication(after)
+	return next, !next.IsZero()
+}
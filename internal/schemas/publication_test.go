@@ -0,0 +1,64 @@
+package schemas
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrankfurterProvider_NextPublication(t *testing.T) {
+	provider := NewFrankfurterProvider("")
+	berlin := loadLocationOrFixed("Europe/Berlin", 1)
+
+	tests := []struct {
+		after    time.Time
+		expected time.Time
+	}{
+		// Friday morning publishes the same afternoon
+		{time.Date(2026, 2, 20, 9, 0, 0, 0, berlin), time.Date(2026, 2, 20, 16, 30, 0, 0, berlin)},
+		// Friday evening skips the weekend
+		{time.Date(2026, 2, 20, 18, 0, 0, 0, berlin), time.Date(2026, 2, 23, 16, 30, 0, 0, berlin)},
+		// Saturday waits for Monday
+		{time.Date(2026, 2, 21, 12, 0, 0, 0, berlin), time.Date(2026, 2, 23, 16, 30, 0, 0, berlin)},
+		// Maundy Thursday evening skips Good Friday, the weekend and Easter Monday
+		{time.Date(2026, 4, 2, 18, 0, 0, 0, berlin), time.Date(2026, 4, 7, 16, 30, 0, 0, berlin)},
+		// Christmas Eve evening skips both Christmas holidays
+		{time.Date(2025, 12, 24, 18, 0, 0, 0, berlin), time.Date(2025, 12, 29, 16, 30, 0, 0, berlin)},
+	}
+
+	for _, tt := range tests {
+		assert.True(t, tt.expected.Equal(provider.NextPublication(tt.after)),
+			"NextPublication(%v) = %v, want %v", tt.after, provider.NextPublication(tt.after), tt.expected)
+	}
+}
+
+func TestMASProvider_NextPublication(t *testing.T) {
+	provider := NewMASProvider("", "")
+	singapore := loadLocationOrFixed("Asia/Singapore", 8)
+
+	next := provider.NextPublication(time.Date(2026, 2, 20, 13, 0, 0, 0, singapore))
+	assert.True(t, time.Date(2026, 2, 23, 12, 0, 0, 0, singapore).Equal(next))
+}
+
+func TestIsTARGETHoliday(t *testing.T) {
+	tests := []struct {
+		date     time.Time
+		expected bool
+	}{
+		{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 4, 6, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 12, 26, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC), false},
+		{time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, IsTARGETHoliday(tt.date), "IsTARGETHoliday(%v)", tt.date.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,28 @@
+package schemas
+
+import (
+	"time"
+)
+
+type StoredRate struct {
+	ID       int     `json:"id,omitempty"`
+	Source   string  `json:"source"`
+	Base     string  `json:"base"`
+	Currency string  `json:"currency"`
+	Date     string  `json:"date"`
+	Rate     float64 `json:"rate"`
+}
+
+type RateHistoryStore interface {
+	GetStoredRates(base, currency string, start, end time.Time) ([]HistoricalRate, error)
+	GetStoredRatesOnDate(base, date string) ([]StoredRate, error)
+	InsertStoredRates(rates []StoredRate) error
+}
+
+func (r StoredRate) ToHistoricalRate() (HistoricalRate, error) {
+	date, err := time.Parse("2006-01-02", r.Date)
+	if err != nil {
+		return HistoricalRate{}, err
+	}
+	return HistoricalRate{Date: date, Rate: r.Rate}, nil
+}
@@ -0,0 +1,80 @@
+package schemas
+
+import (
+	"fmt"
+	"time"
+)
+
+type ExchangeRate struct {
+	Base       string
+	Currency   string
+	Rate       float64
+	Date       string
+	Source     string
+	Via        string
+	CrossCheck *CrossCheck
+}
+
+type CrossCheck struct {
+	Source    string
+	Rate      float64
+	Date      string
+	Deviation float64
+	Exceeded  bool
+}
+
+func (r *ExchangeRate) IsDisputed() bool {
+	return r != nil && r.CrossCheck != nil && r.CrossCheck.Exceeded
+}
+
+type CurrencyInfo struct {
+	Code string
+	Name string
+}
+
+// Batch methods return an entry for every requested currency the provider could
+// quote; currencies it has no rate for are left out of the map rather than
+// failing the whole request.
+type RateProvider interface {
+	Name() string
+	LatestRates(base string, currencies []string) (map[string]*ExchangeRate, error)
+	HistoricalRates(base string, currencies []string, start, end time.Time) (map[string][]HistoricalRate, error)
+	SupportedCurrencies() ([]CurrencyInfo, error)
+}
+
+func QuoteCurrencies(base string, currencies []string) []string {
+	seen := make(map[string]bool, len(currencies))
+	quoted := make([]string, 0, len(currencies))
+	for _, currency := range currencies {
+		if currency == base || seen[currency] {
+			continue
+		}
+		seen[currency] = true
+		quoted = append(quoted, currency)
+	}
+	return quoted
+}
+
+func FetchLatestRate(provider RateProvider, base, currency string) (*ExchangeRate, error) {
+	quotes, err := provider.LatestRates(base, []string{currency})
+	if err != nil {
+		return nil, err
+	}
+	quote, ok := quotes[currency]
+	if !ok {
+		return nil, fmt.Errorf("rate not available for currency: %s", currency)
+	}
+	return quote, nil
+}
+
+func FetchHistoricalRates(provider RateProvider, base, currency string, start, end time.Time) ([]HistoricalRate, error) {
+	histories, err := provider.HistoricalRates(base, []string{currency}, start, end)
+	if err != nil {
+		return nil, err
+	}
+	rates, ok := histories[currency]
+	if !ok {
+		return nil, fmt.Errorf("rate not available for currency: %s", currency)
+	}
+	return rates, nil
+}
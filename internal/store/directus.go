@@ -0,0 +1,189 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/directus"
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
+)
+
+const (
+	subscriptionsCollection = "notifybot_currency_subscriptions"
+	chatSettingsCollection  = "notifybot_chat_settings"
+	exchangeRatesCollection = "notifybot_exchange_rates"
+	notificationsCollection = "notifybot_notifications"
+)
+
+type DirectusSubscriptionStore struct{}
+
+type DirectusChatSettingsStore struct{}
+
+type DirectusNotificationStore struct{}
+
+func directusClient() *directus.Client {
+	return directus.New(utils.DirectusHost, utils.DirectusToken)
+}
+
+func chatIDFilter(chatID int64) directus.Filter {
+	return directus.Eq("chat_id", strconv.FormatInt(chatID, 10))
+}
+
+func (DirectusSubscriptionStore) Create(ctx context.Context, sub *schemas.CurrencySubscription) error {
+	created, err := directus.Create(ctx, directusClient(), subscriptionsCollection, sub)
+	if err != nil {
+		return fmt.Errorf("error creating subscription: %w", err)
+	}
+	sub.ID = created.ID
+	return nil
+}
+
+func (DirectusSubscriptionStore) Update(ctx context.Context, sub *schemas.CurrencySubscription) error {
+	if sub.ID == "" {
+		return fmt.Errorf("cannot update subscription without ID")
+	}
+	next := *sub
+	next.Version++
+	updated, err := directus.UpdateByQuery[schemas.CurrencySubscription](ctx, directusClient(), subscriptionsCollection, directus.Query{
+		Filter: directus.And(directus.Eq("id", sub.ID), directus.Eq("version", sub.Version)),
+	}, next)
+	if err != nil {
+		return fmt.Errorf("error updating subscription: %w", err)
+	}
+	if len(updated) == 0 {
+		current, err := DirectusSubscriptionStore{}.Get(ctx, sub.ID)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return fmt.Errorf("%w: %s", ErrAlertNotFound, sub.ID)
+		}
+		// The HTTP client retries a PATCH whose response was lost. If the
+		// first attempt was applied, the retry matches no rows but the stored
+		// record is the one being written.
+		if !isAppliedUpdate(*current, next) {
+			return fmt.Errorf("%w: %s", ErrVersionConflict, sub.ID)
+		}
+	}
+	sub.Version = next.Version
+	return nil
+}
+
+// isAppliedUpdate reports whether stored is the record written by an update
+// to next. Timestamps are compared to the second, as Directus stores them.
+func isAppliedUpdate(stored, next schemas.CurrencySubscription) bool {
+	if !stored.LastNotificationTime.Truncate(time.Second).Equal(next.LastNotificationTime.Truncate(time.Second)) {
+		return false
+	}
+	stored.LastNotificationTime, next.LastNotificationTime = time.Time{}, time.Time{}
+	stored.BaseCurrency, next.BaseCurrency = stored.Base(), next.Base()
+	if len(stored.Ladder) == 0 && len(next.Ladder) == 0 {
+		stored.Ladder, next.Ladder = nil, nil
+	}
+	return reflect.DeepEqual(stored, next)
+}
+
+func (DirectusSubscriptionStore) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("cannot delete subscription without ID")
+	}
+	if err := directus.Delete(ctx, directusClient(), subscriptionsCollection, id); err != nil {
+		return fmt.Errorf("error deleting subscription: %w", err)
+	}
+	return nil
+}
+
+func (DirectusSubscriptionStore) Get(ctx context.Context, id string) (*schemas.CurrencySubscription, error) {
+	sub, err := directus.First[schemas.CurrencySubscription](ctx, directusClient(), subscriptionsCollection, directus.Eq("id", id))
+	if err != nil {
+		return nil, fmt.Errorf("error getting subscription: %w", err)
+	}
+	return sub, nil
+}
+
+func (DirectusSubscriptionStore) ListByChat(ctx context.Context, chatID int64) ([]schemas.CurrencySubscription, error) {
+	subscriptions, err := directus.Search[schemas.CurrencySubscription](ctx, directusClient(), subscriptionsCollection, directus.Query{
+		Filter: chatIDFilter(chatID),
+		Sort:   []string{"base_currency", "currency", "id"},
+		Limit:  directus.All,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (DirectusSubscriptionStore) ListActivePage(ctx context.Context, afterID string, limit int) ([]schemas.CurrencySubscription, error) {
+	filter := directus.Eq("enabled", true)
+	if afterID != "" {
+		filter = directus.And(filter, directus.Gt("id", afterID))
+	}
+	subscriptions, err := directus.Search[schemas.CurrencySubscription](ctx, directusClient(), subscriptionsCollection, directus.Query{
+		Filter: filter,
+		Sort:   []string{"id"},
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (DirectusChatSettingsStore) Create(ctx context.Context, chatSettings *schemas.ChatSettings) error {
+	if _, err := directus.Create(ctx, directusClient(), chatSettingsCollection, chatSettings); err != nil {
+		return fmt.Errorf("error inserting chat settings to directus: %w", err)
+	}
+	return nil
+}
+
+func (DirectusChatSettingsStore) Update(ctx context.Context, chatSettings *schemas.ChatSettings) error {
+	id := strconv.FormatInt(chatSettings.ChatId, 10)
+	if _, err := directus.Update(ctx, directusClient(), chatSettingsCollection, id, chatSettings); err != nil {
+		return fmt.Errorf("error updating chat settings in directus: %w", err)
+	}
+	return nil
+}
+
+func (DirectusChatSettingsStore) Delete(ctx context.Context, chatID int64) error {
+	if err := directus.Delete(ctx, directusClient(), chatSettingsCollection, strconv.FormatInt(chatID, 10)); err != nil {
+		return fmt.Errorf("error deleting chat settings in directus: %w", err)
+	}
+	return nil
+}
+
+func (DirectusChatSettingsStore) Get(ctx context.Context, chatID int64) (*schemas.ChatSettings, error) {
+	chatSettings, err := directus.First[schemas.ChatSettings](ctx, directusClient(), chatSettingsCollection, chatIDFilter(chatID))
+	if err != nil {
+		return nil, fmt.Errorf("error getting chat settings in directus: %w", err)
+	}
+	return chatSettings, nil
+}
+
+func (DirectusNotificationStore) Create(ctx context.Context, notification *schemas.Notification) error {
+	created, err := directus.Create(ctx, directusClient(), notificationsCollection, notification)
+	if err != nil {
+		return fmt.Errorf("error creating notification: %w", err)
+	}
+	notification.ID = created.ID
+	return nil
+}
+
+func (DirectusNotificationStore) ListByChat(ctx context.Context, chatID int64, currency string, limit int) ([]schemas.Notification, error) {
+	filter := chatIDFilter(chatID)
+	if currency != "" {
+		filter = directus.And(filter, directus.Eq("currency", currency))
+	}
+	notifications, err := directus.Search[schemas.Notification](ctx, directusClient(), notificationsCollection, directus.Query{
+		Filter: filter,
+		Sort:   []string{"-sent_at"},
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting notifications: %w", err)
+	}
+	return notifications, nil
+}
@@ -0,0 +1,419 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/directus"
+	log "github.com/sirupsen/logrus"
+)
+
+const migrationsCollection = "notifybot_schema_migrations"
+
+// A DirectusMigration lists collections and fields that must exist once it has
+// run. Collections that are missing are created with all their fields, and
+// fields missing from existing collections are added, so every migration can
+// be re-run safely against a partially migrated or hand-built instance.
+type DirectusMigration struct {
+	Version     int
+	Name        string
+	Collections []directus.Collection
+}
+
+type appliedMigration struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+func inputField(field, fieldType string, nullable bool) directus.Field {
+	return directus.Field{
+		Field: field,
+		Type:  fieldType,
+		Meta:  map[string]any{"interface": "input", "width": "half"},
+		Schema: map[string]any{
+			"is_nullable": nullable,
+		},
+	}
+}
+
+func decimalField(field string, nullable bool) directus.Field {
+	f := inputField(field, "float", nullable)
+	f.Meta["special"] = []string{"cast-decimal"}
+	return f
+}
+
+func timestampField(field, special string) directus.Field {
+	return directus.Field{
+		Field: field,
+		Type:  "timestamp",
+		Meta: map[string]any{
+			"special":         []string{special},
+			"interface":       "datetime",
+			"readonly":        true,
+			"hidden":          true,
+			"width":           "half",
+			"display":         "datetime",
+			"display_options": map[string]any{"relative": true},
+		},
+		Schema: map[string]any{},
+	}
+}
+
+func primaryKeyField(field, fieldType string, meta, schema map[string]any) directus.Field {
+	fieldMeta := map[string]any{"hidden": true, "interface": "input", "readonly": true}
+	for k, v := range meta {
+		fieldMeta[k] = v
+	}
+	fieldSchema := map[string]any{"is_primary_key": true}
+	for k, v := range schema {
+		fieldSchema[k] = v
+	}
+	return directus.Field{Field: field, Type: fieldType, Meta: fieldMeta, Schema: fieldSchema}
+}
+
+func collection(name string, fields ...directus.Field) directus.Collection {
+	return directus.Collection{
+		Collection: name,
+		Meta:       map[string]any{"singleton": false},
+		Schema:     map[string]any{},
+		Fields:     fields,
+	}
+}
+
+func withDefault(f directus.Field, value any) directus.Field {
+	f.Schema["default_value"] = value
+	return f
+}
+
+var DirectusMigrations = []DirectusMigration{
+	{
+		Version: 1,
+		Name:    "create chat settings and currency subscriptions",
+		Collections: []directus.Collection{
+			collection(chatSettingsCollection,
+				primaryKeyField("chat_id", "string", nil, nil),
+				timestampField("date_created", "date-created"),
+			),
+			collection(subscriptionsCollection,
+				primaryKeyField("id", "uuid", map[string]any{"special": []string{"uuid"}}, nil),
+				inputField("chat_id", "string", false),
+				inputField("currency", "string", false),
+				decimalField("threshold_above", true),
+				decimalField("threshold_below", true),
+				decimalField("interval", true),
+				withDefault(decimalField("last_notified_rate", false), 0),
+				directus.Field{
+					Field:  "last_notification_time",
+					Type:   "timestamp",
+					Meta:   map[string]any{"interface": "datetime", "width": "half"},
+					Schema: map[string]any{"is_nullable": true},
+				},
+				directus.Field{
+					Field:  "enabled",
+					Type:   "boolean",
+					Meta:   map[string]any{"interface": "boolean", "width": "half", "display": "boolean"},
+					Schema: map[string]any{"default_value": true, "is_nullable": false},
+				},
+				timestampField("date_created", "date-created"),
+				timestampField("date_updated", "date-updated"),
+			),
+		},
+	},
+	{
+		Version: 2,
+		Name:    "add base currency",
+		Collections: []directus.Collection{
+			collection(chatSettingsCollection, withDefault(inputField("base_currency", "string", false), "SGD")),
+			collection(subscriptionsCollection, withDefault(inputField("base_currency", "string", false), "SGD")),
+		},
+	},
+	{
+		Version: 3,
+		Name:    "create exchange rate history",
+		Collections: []directus.Collection{
+			collection(exchangeRatesCollection,
+				primaryKeyField("id", "integer", nil, map[string]any{"has_auto_increment": true}),
+				inputField("source", "string", false),
+				inputField("base", "string", false),
+				inputField("currency", "string", false),
+				directus.Field{
+					Field:  "date",
+					Type:   "date",
+					Meta:   map[string]any{"interface": "datetime", "width": "half"},
+					Schema: map[string]any{"is_nullable": false, "is_indexed": true},
+				},
+				inputField("rate", "float", false),
+			),
+		},
+	},
+	{
+		Version: 4,
+		Name:    "create notification log",
+		Collections: []directus.Collection{
+			collection(notificationsCollection,
+				primaryKeyField("id", "uuid", map[string]any{"special": []string{"uuid"}}, nil),
+				inputField("subscription_id", "string", false),
+				directus.Field{
+					Field:  "chat_id",
+					Type:   "string",
+					Meta:   map[string]any{"interface": "input", "width": "half"},
+					Schema: map[string]any{"is_nullable": false, "is_indexed": true},
+				},
+				inputField("base_currency", "string", false),
+				inputField("currency", "string", false),
+				decimalField("rate", false),
+				inputField("trigger", "string", false),
+				inputField("data_date", "string", true),
+				inputField("message_id", "bigInteger", true),
+				inputField("status", "string", false),
+				inputField("error", "text", true),
+				directus.Field{
+					Field:  "sent_at",
+					Type:   "timestamp",
+					Meta:   map[string]any{"interface": "datetime", "width": "half"},
+					Schema: map[string]any{"is_nullable": false},
+				},
+			),
+		},
+	},
+	{
+		Version: 5,
+		Name:    "add subscription version",
+		Collections: []directus.Collection{
+			collection(subscriptionsCollection, withDefault(inputField("version", "integer", false), 0)),
+		},
+	},
+	{
+		Version: 6,
+		Name:    "add percentage alerts",
+		Collections: []directus.Collection{
+			collection(subscriptionsCollection,
+				decimalField("threshold_above_percent", true),
+				decimalField("threshold_below_percent", true),
+				decimalField("interval_percent", true),
+				withDefault(decimalField("reference_rate", false), 0),
+			),
+		},
+	},
+	{
+		Version: 7,
+		Name:    "add repeating thresholds",
+		Collections: []directus.Collection{
+			collection(subscriptionsCollection,
+				directus.Field{
+					Field:  "repeat",
+					Type:   "boolean",
+					Meta:   map[string]any{"interface": "boolean", "width": "half", "display": "boolean"},
+					Schema: map[string]any{"default_value": false, "is_nullable": false},
+				},
+				decimalField("hysteresis", true),
+				decimalField("hysteresis_percent", true),
+				withDefault(inputField("triggered", "string", false), ""),
+			),
+		},
+	},
+	{
+		Version: 8,
+		Name:    "add threshold ladders",
+		Collections: []directus.Collection{
+			collection(subscriptionsCollection,
+				directus.Field{
+					Field:  "ladder",
+					Type:   "json",
+					Meta:   map[string]any{"interface": "input-code", "options": map[string]any{"language": "JSON"}, "special": []string{"cast-json"}},
+					Schema: map[string]any{"is_nullable": true},
+				},
+				withDefault(inputField("ladder_direction", "string", false), ""),
+			),
+		},
+	},
+	{
+		Version: 9,
+		Name:    "add indicator alerts",
+		Collections: []directus.Collection{
+			collection(subscriptionsCollection,
+				directus.Field{
+					Field:  "indicator",
+					Type:   "json",
+					Meta:   map[string]any{"interface": "input-code", "options": map[string]any{"language": "JSON"}, "special": []string{"cast-json"}},
+					Schema: map[string]any{"is_nullable": true},
+				},
+			),
+		},
+	},
+}
+
+var migrationsMetadata = collection(migrationsCollection,
+	primaryKeyField("version", "integer", nil, nil),
+	inputField("name", "string", false),
+	directus.Field{
+		Field:  "applied_at",
+		Type:   "timestamp",
+		Meta:   map[string]any{"interface": "datetime", "width": "half"},
+		Schema: map[string]any{"is_nullable": false},
+	},
+)
+
+func existingCollections(ctx context.Context, c *directus.Client) (map[string]bool, error) {
+	collections, err := c.Collections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(collections))
+	for _, col := range collections {
+		existing[col.Collection] = true
+	}
+	return existing, nil
+}
+
+func existingFields(ctx context.Context, c *directus.Client, collection string) (map[string]bool, error) {
+	fields, err := c.Fields(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		existing[f.Field] = true
+	}
+	return existing, nil
+}
+
+func ensureCollection(ctx context.Context, c *directus.Client, existing map[string]bool, col directus.Collection) error {
+	if !existing[col.Collection] {
+		log.Infof("Creating Directus collection %s", col.Collection)
+		if err := c.CreateCollection(ctx, col); err != nil {
+			return fmt.Errorf("creating collection %s: %w", col.Collection, err)
+		}
+		existing[col.Collection] = true
+		return nil
+	}
+
+	fields, err := existingFields(ctx, c, col.Collection)
+	if err != nil {
+		return fmt.Errorf("reading fields of %s: %w", col.Collection, err)
+	}
+	for _, f := range col.Fields {
+		if fields[f.Field] {
+			continue
+		}
+		log.Infof("Adding field %s.%s", col.Collection, f.Field)
+		if err := c.CreateField(ctx, col.Collection, f); err != nil {
+			return fmt.Errorf("adding field %s.%s: %w", col.Collection, f.Field, err)
+		}
+	}
+	return nil
+}
+
+func appliedDirectusVersions(ctx context.Context, c *directus.Client) (map[int]bool, error) {
+	applied, err := directus.Search[appliedMigration](ctx, c, migrationsCollection, directus.Query{Limit: directus.All})
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[int]bool, len(applied))
+	for _, m := range applied {
+		versions[m.Version] = true
+	}
+	return versions, nil
+}
+
+// MigrateDirectus applies every migration not yet recorded in the
+// notifybot_schema_migrations collection and returns how many it applied.
+func MigrateDirectus(ctx context.Context, migrations []DirectusMigration) (int, error) {
+	c := directusClient()
+	existing, err := existingCollections(ctx, c)
+	if err != nil {
+		return 0, fmt.Errorf("listing collections: %w", err)
+	}
+	if err := ensureCollection(ctx, c, existing, migrationsMetadata); err != nil {
+		return 0, err
+	}
+	applied, err := appliedDirectusVersions(ctx, c)
+	if err != nil {
+		return 0, fmt.Errorf("reading applied migrations: %w", err)
+	}
+
+	sorted := append([]DirectusMigration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	count := 0
+	for _, m := range sorted {
+		if applied[m.Version] {
+			continue
+		}
+		for _, col := range m.Collections {
+			if err := ensureCollection(ctx, c, existing, col); err != nil {
+				return count, fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+			}
+		}
+		record := appliedMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now().UTC()}
+		if _, err := directus.Create(ctx, c, migrationsCollection, record); err != nil {
+			return count, fmt.Errorf("recording migration %d: %w", m.Version, err)
+		}
+		log.Infof("Applied Directus migration %d: %s", m.Version, m.Name)
+		count++
+	}
+	return count, nil
+}
+
+// VerifyDirectusSchema checks that every field the migrations declare exists,
+// so a missing migration fails at startup rather than on the first write.
+func VerifyDirectusSchema(ctx context.Context, migrations []DirectusMigration) error {
+	c := directusClient()
+	required := make(map[string][]string)
+	latest := 0
+	for _, m := range migrations {
+		for _, col := range m.Collections {
+			for _, f := range col.Fields {
+				required[col.Collection] = append(required[col.Collection], f.Field)
+			}
+		}
+		if m.Version > latest {
+			latest = m.Version
+		}
+	}
+
+	existing, err := existingCollections(ctx, c)
+	if err != nil {
+		return fmt.Errorf("listing collections: %w", err)
+	}
+
+	var missing []string
+	names := make([]string, 0, len(required))
+	for name := range required {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !existing[name] {
+			missing = append(missing, name)
+			continue
+		}
+		fields, err := existingFields(ctx, c, name)
+		if err != nil {
+			return fmt.Errorf("reading fields of %s: %w", name, err)
+		}
+		for _, f := range required[name] {
+			if !fields[f] {
+				missing = append(missing, name+"."+f)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("directus schema is missing %s; run `notifybot migrate`", strings.Join(missing, ", "))
+	}
+
+	if existing[migrationsCollection] {
+		applied, err := appliedDirectusVersions(ctx, c)
+		if err != nil {
+			return fmt.Errorf("reading applied migrations: %w", err)
+		}
+		if !applied[latest] {
+			log.Warnf("Directus schema has the required fields but migration %d is not recorded; run `notifybot migrate`", latest)
+		}
+	}
+	return nil
+}
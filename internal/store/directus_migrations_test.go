@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/directus"
+	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDirectusSchema struct {
+	mu          sync.Mutex
+	fields      map[string][]string
+	items       map[string][]json.RawMessage
+	fieldWrites int
+}
+
+func (f *fakeDirectusSchema) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reply := func(data any) {
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && parts[0] == "collections":
+		var collections []directus.Collection
+		for name := range f.fields {
+			collections = append(collections, directus.Collection{Collection: name})
+		}
+		reply(collections)
+	case r.Method == http.MethodPost && parts[0] == "collections":
+		var col directus.Collection
+		json.NewDecoder(r.Body).Decode(&col)
+		f.fields[col.Collection] = nil
+		for _, field := range col.Fields {
+			f.fields[col.Collection] = append(f.fields[col.Collection], field.Field)
+		}
+		reply(col)
+	case r.Method == http.MethodGet && parts[0] == "fields":
+		var fields []directus.Field
+		for _, name := range f.fields[parts[1]] {
+			fields = append(fields, directus.Field{Collection: parts[1], Field: name})
+		}
+		reply(fields)
+	case r.Method == http.MethodPost && parts[0] == "fields":
+		var field directus.Field
+		json.NewDecoder(r.Body).Decode(&field)
+		f.fields[parts[1]] = append(f.fields[parts[1]], field.Field)
+		f.fieldWrites++
+		reply(field)
+	case r.Method == "SEARCH" && parts[0] == "items":
+		reply(f.items[parts[1]])
+	case r.Method == http.MethodPost && parts[0] == "items":
+		var item json.RawMessage
+		json.NewDecoder(r.Body).Decode(&item)
+		f.items[parts[1]] = append(f.items[parts[1]], item)
+		reply(item)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]any{"errors": []map[string]any{{"message": "Route doesn't exist.", "extensions": map[string]any{"code": directus.CodeRouteNotFound}}}})
+	}
+}
+
+func useFakeDirectus(t *testing.T, fields map[string][]string) *fakeDirectusSchema {
+	fake := &fakeDirectusSchema{fields: fields, items: make(map[string][]json.RawMessage)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	host, token := utils.DirectusHost, utils.DirectusToken
+	utils.DirectusHost, utils.DirectusToken = server.URL, "token"
+	t.Cleanup(func() { utils.DirectusHost, utils.DirectusToken = host, token })
+	return fake
+}
+
+func TestMigrateDirectus_FreshInstance(t *testing.T) {
+	ctx := context.Background()
+	fake := useFakeDirectus(t, map[string][]string{})
+
+	require.Error(t, VerifyDirectusSchema(ctx, DirectusMigrations))
+
+	applied, err := MigrateDirectus(ctx, DirectusMigrations)
+	require.NoError(t, err)
+	assert.Equal(t, len(DirectusMigrations), applied)
+	assert.Contains(t, fake.fields[subscriptionsCollection], "base_currency")
+	assert.Contains(t, fake.fields[exchangeRatesCollection], "rate")
+	assert.Len(t, fake.items[migrationsCollection], len(DirectusMigrations))
+	require.NoError(t, VerifyDirectusSchema(ctx, DirectusMigrations))
+
+	applied, err = MigrateDirectus(ctx, DirectusMigrations)
+	require.NoError(t, err)
+	assert.Equal(t, 0, applied)
+}
+
+func TestMigrateDirectus_AdoptsExistingSchema(t *testing.T) {
+	ctx := context.Background()
+	fake := useFakeDirectus(t, map[string][]string{
+		chatSettingsCollection:  {"chat_id", "date_created"},
+		subscriptionsCollection: {"id", "chat_id", "currency", "threshold_above", "threshold_below", "interval", "last_notified_rate", "last_notification_time", "enabled", "date_created", "date_updated"},
+	})
+
+	err := VerifyDirectusSchema(ctx, DirectusMigrations)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "notifybot_chat_settings.base_currency")
+	assert.Contains(t, err.Error(), "notifybot_exchange_rates")
+
+	applied, err := MigrateDirectus(ctx, DirectusMigrations)
+	require.NoError(t, err)
+	assert.Equal(t, len(DirectusMigrations), applied)
+	assert.Equal(t, 14, fake.fieldWrites)
+	assert.Contains(t, fake.fields[subscriptionsCollection], "version")
+	require.NoError(t, VerifyDirectusSchema(ctx, DirectusMigrations))
+}
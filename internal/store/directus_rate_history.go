@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/directus"
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+)
+
+type DirectusRateHistory struct{}
+
+func searchStoredRates(filter directus.Filter) ([]schemas.StoredRate, error) {
+	rates, err := directus.Search[schemas.StoredRate](context.Background(), directusClient(), exchangeRatesCollection, directus.Query{
+		Filter: filter,
+		Sort:   []string{"date"},
+		Limit:  directus.All,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting stored rates: %w", err)
+	}
+	return rates, nil
+}
+
+func (DirectusRateHistory) GetStoredRates(base, currency string, start, end time.Time) ([]schemas.HistoricalRate, error) {
+	stored, err := searchStoredRates(directus.And(
+		directus.Eq("base", base),
+		directus.Eq("currency", currency),
+		directus.Between("date", start.Format("2006-01-02"), end.Format("2006-01-02")),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]schemas.HistoricalRate, 0, len(stored))
+	for _, r := range stored {
+		rate, err := r.ToHistoricalRate()
+		if err != nil {
+			continue
+		}
+		rates = append(rates, rate)
+	}
+
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].Date.Before(rates[j].Date)
+	})
+
+	return rates, nil
+}
+
+func (DirectusRateHistory) GetStoredRatesOnDate(base, date string) ([]schemas.StoredRate, error) {
+	return searchStoredRates(directus.And(
+		directus.Eq("base", base),
+		directus.Eq("date", date),
+	))
+}
+
+func (DirectusRateHistory) InsertStoredRates(rates []schemas.StoredRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	if err := directus.CreateMany(context.Background(), directusClient(), exchangeRatesCollection, rates); err != nil {
+		return fmt.Errorf("error inserting stored rates: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/directus"
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSyncMinRetryDelay = time.Second
+	defaultSyncMaxRetryDelay = time.Minute
+)
+
+// DirectusSync mirrors the subscription and chat settings collections into
+// memory from Directus realtime events, so edits made in the Directus UI are
+// seen by the next read. Until both collections have been loaded over the
+// socket, and whenever it drops, the synced stores query Directus directly.
+type DirectusSync struct {
+	client        *directus.Client
+	subscriptions *MemorySubscriptionStore
+	chatSettings  *MemoryChatSettingsStore
+
+	MinRetryDelay time.Duration
+	MaxRetryDelay time.Duration
+
+	live atomic.Bool
+	mu   sync.Mutex
+	// loaded tracks which collections have sent their init event on the
+	// current connection.
+	loaded map[string]bool
+}
+
+func NewDirectusSync(client *directus.Client) *DirectusSync {
+	return &DirectusSync{
+		client:        client,
+		subscriptions: NewMemorySubscriptionStore(),
+		chatSettings:  NewMemoryChatSettingsStore(),
+		MinRetryDelay: defaultSyncMinRetryDelay,
+		MaxRetryDelay: defaultSyncMaxRetryDelay,
+		loaded:        make(map[string]bool),
+	}
+}
+
+// StartDirectusSync wraps the configured Directus stores so reads are served
+// from a realtime index, and keeps the index up to date until ctx is done.
+func StartDirectusSync(ctx context.Context) *DirectusSync {
+	s := NewDirectusSync(directusClient())
+	Subscriptions = SyncedSubscriptionStore{remote: Subscriptions, sync: s}
+	ChatSettings = SyncedChatSettingsStore{remote: ChatSettings, sync: s}
+	go s.Run(ctx)
+	return s
+}
+
+// Live reports whether the index is loaded and the socket is connected.
+func (s *DirectusSync) Live() bool {
+	return s.live.Load()
+}
+
+// Run keeps a realtime connection open, reconnecting with exponential backoff
+// until ctx is cancelled.
+func (s *DirectusSync) Run(ctx context.Context) {
+	delay := s.MinRetryDelay
+	for {
+		err := s.client.Subscribe(ctx, []string{subscriptionsCollection, chatSettingsCollection}, s.apply)
+		wasLive := s.live.Swap(false)
+		s.mu.Lock()
+		s.loaded = make(map[string]bool)
+		s.mu.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+
+		if wasLive {
+			delay = s.MinRetryDelay
+			log.Warnf("Directus realtime connection lost, querying Directus directly until it reconnects: %v", err)
+		} else {
+			log.Debugf("Directus realtime connection failed, retrying in %v: %v", delay, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, s.MaxRetryDelay)
+	}
+}
+
+func (s *DirectusSync) apply(event directus.Event) error {
+	var err error
+	switch event.Collection {
+	case subscriptionsCollection:
+		err = s.applySubscriptions(event)
+	case chatSettingsCollection:
+		err = s.applyChatSettings(event)
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("applying %s %s event: %w", event.Collection, event.Event, err)
+	}
+
+	if event.Event == directus.EventInit {
+		s.mu.Lock()
+		s.loaded[event.Collection] = true
+		ready := s.loaded[subscriptionsCollection] && s.loaded[chatSettingsCollection]
+		s.mu.Unlock()
+		if ready && !s.live.Swap(true) {
+			log.Info("Directus realtime sync connected")
+		}
+	}
+	return nil
+}
+
+func (s *DirectusSync) applySubscriptions(event directus.Event) error {
+	if event.Event == directus.EventDelete {
+		ids, err := event.DeletedKeys()
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			s.subscriptions.remove(id)
+		}
+		return nil
+	}
+
+	var subscriptions []schemas.CurrencySubscription
+	if err := json.Unmarshal(event.Data, &subscriptions); err != nil {
+		return err
+	}
+	if event.Event == directus.EventInit {
+		s.subscriptions.replace(subscriptions)
+		return nil
+	}
+	for _, sub := range subscriptions {
+		s.subscriptions.put(sub)
+	}
+	return nil
+}
+
+func (s *DirectusSync) applyChatSettings(event directus.Event) error {
+	if event.Event == directus.EventDelete {
+		keys, err := event.DeletedKeys()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			chatID, err := strconv.ParseInt(key, 10, 64)
+			if err != nil {
+				return err
+			}
+			s.chatSettings.remove(chatID)
+		}
+		return nil
+	}
+
+	var settings []schemas.ChatSettings
+	if err := json.Unmarshal(event.Data, &settings); err != nil {
+		return err
+	}
+	if event.Event == directus.EventInit {
+		s.chatSettings.replace(settings)
+		return nil
+	}
+	for _, cs := range settings {
+		s.chatSettings.put(cs)
+	}
+	return nil
+}
+
+// SyncedSubscriptionStore writes through to remote and reads from the
+// realtime index while it is live.
+type SyncedSubscriptionStore struct {
+	remote SubscriptionStore
+	sync   *DirectusSync
+}
+
+func (s SyncedSubscriptionStore) Create(ctx context.Context, sub *schemas.CurrencySubscription) error {
+	if err := s.remote.Create(ctx, sub); err != nil {
+		return err
+	}
+	s.sync.subscriptions.put(*sub)
+	return nil
+}
+
+func (s SyncedSubscriptionStore) Update(ctx context.Context, sub *schemas.CurrencySubscription) error {
+	if err := s.remote.Update(ctx, sub); err != nil {
+		return err
+	}
+	s.sync.subscriptions.put(*sub)
+	return nil
+}
+
+func (s SyncedSubscriptionStore) Delete(ctx context.Context, id string) error {
+	if err := s.remote.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.sync.subscriptions.remove(id)
+	return nil
+}
+
+func (s SyncedSubscriptionStore) Get(ctx context.Context, id string) (*schemas.CurrencySubscription, error) {
+	if !s.sync.Live() {
+		return s.remote.Get(ctx, id)
+	}
+	return s.sync.subscriptions.Get(ctx, id)
+}
+
+func (s SyncedSubscriptionStore) ListByChat(ctx context.Context, chatID int64) ([]schemas.CurrencySubscription, error) {
+	if !s.sync.Live() {
+		return s.remote.ListByChat(ctx, chatID)
+	}
+	return s.sync.subscriptions.ListByChat(ctx, chatID)
+}
+
+func (s SyncedSubscriptionStore) ListActivePage(ctx context.Context, afterID string, limit int) ([]schemas.CurrencySubscription, error) {
+	if !s.sync.Live() {
+		return s.remote.ListActivePage(ctx, afterID, limit)
+	}
+	return s.sync.subscriptions.ListActivePage(ctx, afterID, limit)
+}
+
+type SyncedChatSettingsStore struct {
+	remote ChatSettingsStore
+	sync   *DirectusSync
+}
+
+func (s SyncedChatSettingsStore) Create(ctx context.Context, settings *schemas.ChatSettings) error {
+	if err := s.remote.Create(ctx, settings); err != nil {
+		return err
+	}
+	s.sync.chatSettings.put(*settings)
+	return nil
+}
+
+func (s SyncedChatSettingsStore) Update(ctx context.Context, settings *schemas.ChatSettings) error {
+	if err := s.remote.Update(ctx, settings); err != nil {
+		return err
+	}
+	s.sync.chatSettings.put(*settings)
+	return nil
+}
+
+func (s SyncedChatSettingsStore) Delete(ctx context.Context, chatID int64) error {
+	if err := s.remote.Delete(ctx, chatID); err != nil {
+		return err
+	}
+	s.sync.chatSettings.remove(chatID)
+	return nil
+}
+
+func (s SyncedChatSettingsStore) Get(ctx context.Context, chatID int64) (*schemas.ChatSettings, error) {
+	if !s.sync.Live() {
+		return s.remote.Get(ctx, chatID)
+	}
+	return s.sync.chatSettings.Get(ctx, chatID)
+}
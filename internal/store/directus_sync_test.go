@@ -0,0 +1,134 @@
+package store
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/directus"
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRealtime accepts Directus realtime connections, answers each subscribe
+// with an init event built from init, then relays events pushed by the test.
+// Closing drop ends the connection and refuses new ones.
+type fakeRealtime struct {
+	init   map[string]any
+	events chan map[string]any
+	drop   chan struct{}
+}
+
+func newFakeRealtime(t *testing.T, init map[string]any) (*fakeRealtime, *directus.Client) {
+	fake := &fakeRealtime{init: init, events: make(chan map[string]any), drop: make(chan struct{})}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-fake.drop:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		default:
+		}
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		ctx := r.Context()
+
+		var msg map[string]any
+		if wsjson.Read(ctx, conn, &msg) != nil {
+			return
+		}
+		wsjson.Write(ctx, conn, map[string]string{"type": "auth", "status": "ok"})
+		for range 2 {
+			if wsjson.Read(ctx, conn, &msg) != nil {
+				return
+			}
+			uid := msg["uid"].(string)
+			wsjson.Write(ctx, conn, map[string]any{"type": "subscription", "event": "init", "uid": uid, "data": fake.init[uid]})
+		}
+		for {
+			select {
+			case event := <-fake.events:
+				wsjson.Write(ctx, conn, event)
+			case <-fake.drop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return fake, directus.New(server.URL, "secret")
+}
+
+func (f *fakeRealtime) push(collection, event string, data any) {
+	f.events <- map[string]any{"type": "subscription", "event": event, "uid": collection, "data": data}
+}
+
+func TestDirectusSync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	above := 1.4
+	fake, client := newFakeRealtime(t, map[string]any{
+		subscriptionsCollection: []schemas.CurrencySubscription{{ID: "sub-1", ChatID: 1, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above, Enabled: true}},
+		chatSettingsCollection:  []schemas.ChatSettings{{ChatId: 1, BaseCurrency: "MYR"}},
+	})
+
+	remoteSubscriptions, remoteSettings := NewMemorySubscriptionStore(), NewMemoryChatSettingsStore()
+	sync := NewDirectusSync(client)
+	sync.MinRetryDelay, sync.MaxRetryDelay = 10*time.Millisecond, 10*time.Millisecond
+	subscriptions := SyncedSubscriptionStore{remote: remoteSubscriptions, sync: sync}
+	settings := SyncedChatSettingsStore{remote: remoteSettings, sync: sync}
+
+	remoteSubscriptions.Create(ctx, &schemas.CurrencySubscription{ChatID: 2, Currency: "EUR", Enabled: true})
+	active, err := subscriptions.ListActivePage(ctx, "", DefaultPageSize)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, "EUR", active[0].Currency)
+
+	go sync.Run(ctx)
+	require.Eventually(t, sync.Live, 5*time.Second, 5*time.Millisecond)
+
+	sub, err := subscriptions.Get(ctx, "sub-1")
+	require.NoError(t, err)
+	require.NotNil(t, sub)
+	assert.Equal(t, above, *sub.ThresholdAbove)
+	cs, err := settings.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "MYR", cs.Base())
+
+	below := 1.3
+	fake.push(subscriptionsCollection, directus.EventUpdate, []schemas.CurrencySubscription{{ID: "sub-1", ChatID: 1, BaseCurrency: "SGD", Currency: "USD", ThresholdBelow: &below, Enabled: true}})
+	require.Eventually(t, func() bool {
+		sub, _ := subscriptions.Get(ctx, "sub-1")
+		return sub.ThresholdBelow != nil
+	}, 5*time.Second, 5*time.Millisecond)
+
+	fake.push(subscriptionsCollection, directus.EventDelete, []string{"sub-1"})
+	fake.push(chatSettingsCollection, directus.EventDelete, []string{"1"})
+	require.Eventually(t, func() bool {
+		cs, _ := settings.Get(ctx, 1)
+		return cs == nil
+	}, 5*time.Second, 5*time.Millisecond)
+	sub, err = subscriptions.Get(ctx, "sub-1")
+	require.NoError(t, err)
+	assert.Nil(t, sub)
+
+	created := &schemas.CurrencySubscription{ChatID: 3, Currency: "JPY", Enabled: true}
+	require.NoError(t, subscriptions.Create(ctx, created))
+	byChat, err := subscriptions.ListByChat(ctx, 3)
+	require.NoError(t, err)
+	assert.Len(t, byChat, 1)
+
+	close(fake.drop)
+	require.Eventually(t, func() bool { return !sync.Live() }, 5*time.Second, 5*time.Millisecond)
+	active, err = subscriptions.ListActivePage(ctx, "", DefaultPageSize)
+	require.NoError(t, err)
+	assert.Len(t, active, 2)
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDirectusItems holds a single subscription and applies versioned
+// updates to it. With lostResponses set, an applied update is answered with a
+// 502 as if a proxy dropped the response.
+type fakeDirectusItems struct {
+	mu            sync.Mutex
+	sub           schemas.CurrencySubscription
+	lostResponses int
+	patches       int
+	sort          []string
+}
+
+func (f *fakeDirectusItems) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reply := func(data any) {
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}
+	switch r.Method {
+	case "SEARCH":
+		var body struct {
+			Query struct {
+				Sort []string `json:"sort"`
+			} `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.sort = body.Query.Sort
+		reply([]schemas.CurrencySubscription{f.sub})
+	case http.MethodPatch:
+		f.patches++
+		var body struct {
+			Query struct {
+				Filter struct {
+					And []map[string]map[string]any `json:"_and"`
+				} `json:"filter"`
+			} `json:"query"`
+			Data schemas.CurrencySubscription `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		version := body.Query.Filter.And[1]["version"]["_eq"]
+		if version != float64(f.sub.Version) {
+			reply([]schemas.CurrencySubscription{})
+			return
+		}
+		f.sub = body.Data
+		if f.lostResponses > 0 {
+			f.lostResponses--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		reply([]schemas.CurrencySubscription{f.sub})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func useFakeDirectusItems(t *testing.T, sub schemas.CurrencySubscription) *fakeDirectusItems {
+	fake := &fakeDirectusItems{sub: sub}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	host, token := utils.DirectusHost, utils.DirectusToken
+	utils.DirectusHost, utils.DirectusToken = server.URL, "token"
+	t.Cleanup(func() { utils.DirectusHost, utils.DirectusToken = host, token })
+	return fake
+}
+
+func TestDirectusSubscriptionStore_UpdateWithLostResponse(t *testing.T) {
+	ctx := context.Background()
+	above := 1.4
+	stored := schemas.CurrencySubscription{ID: "sub-1", ChatID: 1, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above, Enabled: true, Version: 3}
+	fake := useFakeDirectusItems(t, stored)
+	fake.lostResponses = 1
+
+	claimed := stored
+	claimed.MarkNotified(1.41, schemas.TriggerAbove, time.Date(2026, 2, 20, 9, 30, 0, 0, time.UTC))
+	require.NoError(t, DirectusSubscriptionStore{}.Update(ctx, &claimed))
+	assert.Equal(t, 4, claimed.Version)
+	assert.Equal(t, 2, fake.patches, "the lost response is retried")
+	assert.False(t, fake.sub.Enabled)
+
+	stale := stored
+	stale.LastNotifiedRate = 1.5
+	assert.ErrorIs(t, DirectusSubscriptionStore{}.Update(ctx, &stale), ErrVersionConflict)
+}
+
+func TestDirectusSubscriptionStore_ListByChatSortsByPair(t *testing.T) {
+	fake := useFakeDirectusItems(t, schemas.CurrencySubscription{ID: "sub-1", ChatID: 1, BaseCurrency: "SGD", Currency: "USD"})
+
+	subscriptions, err := DirectusSubscriptionStore{}.ListByChat(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Len(t, subscriptions, 1)
+	assert.Equal(t, []string{"base_currency", "currency", "id"}, fake.sort)
+}
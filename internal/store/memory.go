@@ -0,0 +1,307 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+)
+
+type MemorySubscriptionStore struct {
+	mu            sync.RWMutex
+	subscriptions []schemas.CurrencySubscription
+}
+
+func NewMemorySubscriptionStore() *MemorySubscriptionStore {
+	return &MemorySubscriptionStore{}
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func (m *MemorySubscriptionStore) indexOf(id string) int {
+	for i, sub := range m.subscriptions {
+		if sub.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *MemorySubscriptionStore) Create(ctx context.Context, sub *schemas.CurrencySubscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sub.ID = newID()
+	m.subscriptions = append(m.subscriptions, *sub)
+	return nil
+}
+
+func (m *MemorySubscriptionStore) Update(ctx context.Context, sub *schemas.CurrencySubscription) error {
+	if sub.ID == "" {
+		return fmt.Errorf("cannot update subscription without ID")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.indexOf(sub.ID)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrAlertNotFound, sub.ID)
+	}
+	if m.subscriptions[i].Version != sub.Version {
+		return fmt.Errorf("%w: %s", ErrVersionConflict, sub.ID)
+	}
+	sub.Version++
+	m.subscriptions[i] = *sub
+	return nil
+}
+
+func (m *MemorySubscriptionStore) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("cannot delete subscription without ID")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.indexOf(id)
+	if i < 0 {
+		return fmt.Errorf("subscription not found: %s", id)
+	}
+	m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+	return nil
+}
+
+func (m *MemorySubscriptionStore) Get(ctx context.Context, id string) (*schemas.CurrencySubscription, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	i := m.indexOf(id)
+	if i < 0 {
+		return nil, nil
+	}
+	sub := m.subscriptions[i]
+	return &sub, nil
+}
+
+// replace swaps the whole contents of the store, keeping the given IDs.
+func (m *MemorySubscriptionStore) replace(subscriptions []schemas.CurrencySubscription) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscriptions = append([]schemas.CurrencySubscription(nil), subscriptions...)
+}
+
+// put inserts sub, or overwrites the subscription with the same ID.
+func (m *MemorySubscriptionStore) put(sub schemas.CurrencySubscription) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i := m.indexOf(sub.ID); i >= 0 {
+		m.subscriptions[i] = sub
+		return
+	}
+	m.subscriptions = append(m.subscriptions, sub)
+}
+
+func (m *MemorySubscriptionStore) remove(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i := m.indexOf(id); i >= 0 {
+		m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+	}
+}
+
+func (m *MemorySubscriptionStore) filter(keep func(schemas.CurrencySubscription) bool) []schemas.CurrencySubscription {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var subscriptions []schemas.CurrencySubscription
+	for _, sub := range m.subscriptions {
+		if keep(sub) {
+			subscriptions = append(subscriptions, sub)
+		}
+	}
+	return subscriptions
+}
+
+// ListByChat orders the alerts by pair, then ID, like the SQL and Directus
+// stores, so that /fx_list reads the same on every backend.
+func (m *MemorySubscriptionStore) ListByChat(ctx context.Context, chatID int64) ([]schemas.CurrencySubscription, error) {
+	subscriptions := m.filter(func(sub schemas.CurrencySubscription) bool { return sub.ChatID == chatID })
+	sort.Slice(subscriptions, func(i, j int) bool {
+		a, b := subscriptions[i], subscriptions[j]
+		if a.Base() != b.Base() {
+			return a.Base() < b.Base()
+		}
+		if a.Currency != b.Currency {
+			return a.Currency < b.Currency
+		}
+		return a.ID < b.ID
+	})
+	return subscriptions, nil
+}
+
+func (m *MemorySubscriptionStore) ListActivePage(ctx context.Context, afterID string, limit int) ([]schemas.CurrencySubscription, error) {
+	subscriptions := m.filter(func(sub schemas.CurrencySubscription) bool { return sub.Enabled && sub.ID > afterID })
+	sort.Slice(subscriptions, func(i, j int) bool { return subscriptions[i].ID < subscriptions[j].ID })
+	if len(subscriptions) > limit {
+		subscriptions = subscriptions[:limit]
+	}
+	return subscriptions, nil
+}
+
+type MemoryChatSettingsStore struct {
+	mu       sync.RWMutex
+	settings map[int64]schemas.ChatSettings
+}
+
+func NewMemoryChatSettingsStore() *MemoryChatSettingsStore {
+	return &MemoryChatSettingsStore{settings: make(map[int64]schemas.ChatSettings)}
+}
+
+func (m *MemoryChatSettingsStore) Create(ctx context.Context, settings *schemas.ChatSettings) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.settings[settings.ChatId]; ok {
+		return fmt.Errorf("chat settings already exist for chat %d", settings.ChatId)
+	}
+	m.settings[settings.ChatId] = *settings
+	return nil
+}
+
+func (m *MemoryChatSettingsStore) Update(ctx context.Context, settings *schemas.ChatSettings) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.settings[settings.ChatId]; !ok {
+		return fmt.Errorf("chat settings not found for chat %d", settings.ChatId)
+	}
+	m.settings[settings.ChatId] = *settings
+	return nil
+}
+
+func (m *MemoryChatSettingsStore) Delete(ctx context.Context, chatID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.settings[chatID]; !ok {
+		return fmt.Errorf("chat settings not found for chat %d", chatID)
+	}
+	delete(m.settings, chatID)
+	return nil
+}
+
+func (m *MemoryChatSettingsStore) Get(ctx context.Context, chatID int64) (*schemas.ChatSettings, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	settings, ok := m.settings[chatID]
+	if !ok {
+		return nil, nil
+	}
+	return &settings, nil
+}
+
+func (m *MemoryChatSettingsStore) replace(settings []schemas.ChatSettings) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settings = make(map[int64]schemas.ChatSettings, len(settings))
+	for _, cs := range settings {
+		m.settings[cs.ChatId] = cs
+	}
+}
+
+func (m *MemoryChatSettingsStore) put(settings schemas.ChatSettings) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.settings[settings.ChatId] = settings
+}
+
+func (m *MemoryChatSettingsStore) remove(chatID int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.settings, chatID)
+}
+
+type MemoryRateHistory struct {
+	mu    sync.RWMutex
+	rates []schemas.StoredRate
+}
+
+func NewMemoryRateHistory() *MemoryRateHistory {
+	return &MemoryRateHistory{}
+}
+
+func (m *MemoryRateHistory) GetStoredRates(base, currency string, start, end time.Time) ([]schemas.HistoricalRate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	from, to := start.Format("2006-01-02"), end.Format("2006-01-02")
+	var rates []schemas.HistoricalRate
+	for _, r := range m.rates {
+		if r.Base != base || r.Currency != currency || r.Date < from || r.Date > to {
+			continue
+		}
+		rate, err := r.ToHistoricalRate()
+		if err != nil {
+			continue
+		}
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].Date.Before(rates[j].Date)
+	})
+	return rates, nil
+}
+
+func (m *MemoryRateHistory) GetStoredRatesOnDate(base, date string) ([]schemas.StoredRate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var rates []schemas.StoredRate
+	for _, r := range m.rates {
+		if r.Base == base && r.Date == date {
+			rates = append(rates, r)
+		}
+	}
+	return rates, nil
+}
+
+func (m *MemoryRateHistory) InsertStoredRates(rates []schemas.StoredRate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rates = append(m.rates, rates...)
+	return nil
+}
+
+type MemoryNotificationStore struct {
+	mu            sync.RWMutex
+	notifications []schemas.Notification
+}
+
+func NewMemoryNotificationStore() *MemoryNotificationStore {
+	return &MemoryNotificationStore{}
+}
+
+func (m *MemoryNotificationStore) Create(ctx context.Context, notification *schemas.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	notification.ID = newID()
+	m.notifications = append(m.notifications, *notification)
+	return nil
+}
+
+func (m *MemoryNotificationStore) ListByChat(ctx context.Context, chatID int64, currency string, limit int) ([]schemas.Notification, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var notifications []schemas.Notification
+	for i := len(m.notifications) - 1; i >= 0; i-- {
+		n := m.notifications[i]
+		if n.ChatID != chatID || (currency != "" && n.Currency != currency) {
+			continue
+		}
+		notifications = append(notifications, n)
+	}
+	sort.SliceStable(notifications, func(i, j int) bool { return notifications[i].SentAt.After(notifications[j].SentAt) })
+	if limit > 0 && len(notifications) > limit {
+		notifications = notifications[:limit]
+	}
+	return notifications, nil
+}
@@ -0,0 +1,214 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useMemoryStores(t *testing.T) {
+	subscriptions, chatSettings, rateHistory, notifications := Subscriptions, ChatSettings, RateHistory, Notifications
+	require.NoError(t, Configure(BackendMemory))
+	t.Cleanup(func() {
+		Subscriptions, ChatSettings, RateHistory, Notifications = subscriptions, chatSettings, rateHistory, notifications
+	})
+}
+
+func TestConfigure(t *testing.T) {
+	useMemoryStores(t)
+	assert.IsType(t, &MemorySubscriptionStore{}, Subscriptions)
+	assert.IsType(t, &MemoryChatSettingsStore{}, ChatSettings)
+	assert.IsType(t, &MemoryRateHistory{}, RateHistory)
+	assert.IsType(t, &MemoryNotificationStore{}, Notifications)
+
+	require.NoError(t, Configure("Directus"))
+	assert.IsType(t, DirectusSubscriptionStore{}, Subscriptions)
+
+	assert.Error(t, Configure("sqlite3"))
+}
+
+func TestMemorySubscriptionStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemorySubscriptionStore()
+	above := 1.4
+
+	sub := &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above, Enabled: true}
+	require.NoError(t, s.Create(ctx, sub))
+	require.NotEmpty(t, sub.ID)
+	require.NoError(t, s.Create(ctx, &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "EUR"}))
+	require.NoError(t, s.Create(ctx, &schemas.CurrencySubscription{ChatID: 2, BaseCurrency: "SGD", Currency: "USD", Enabled: true}))
+
+	got, err := s.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, sub.ID, got.ID)
+
+	stale := *got
+	got.LastNotifiedRate = 1.35
+	require.NoError(t, s.Update(ctx, got))
+	assert.Equal(t, 1, got.Version)
+	got, _ = s.Get(ctx, sub.ID)
+	assert.Equal(t, 1.35, got.LastNotifiedRate)
+	assert.Equal(t, 1, got.Version)
+
+	stale.Enabled = false
+	assert.ErrorIs(t, s.Update(ctx, &stale), ErrVersionConflict)
+	got, _ = s.Get(ctx, sub.ID)
+	assert.True(t, got.Enabled)
+
+	byChat, err := s.ListByChat(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, byChat, 2)
+	assert.Equal(t, "EUR", byChat[0].Currency, "alerts are listed by pair")
+
+	active, err := s.ListActivePage(ctx, "", DefaultPageSize)
+	require.NoError(t, err)
+	assert.Len(t, active, 2)
+
+	require.NoError(t, s.Delete(ctx, sub.ID))
+	got, err = s.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	assert.Error(t, s.Delete(ctx, sub.ID))
+	assert.ErrorIs(t, s.Update(ctx, sub), ErrAlertNotFound)
+	assert.Error(t, s.Update(ctx, &schemas.CurrencySubscription{}))
+}
+
+func TestMemoryChatSettingsStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryChatSettingsStore()
+
+	got, err := s.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	require.NoError(t, s.Create(ctx, &schemas.ChatSettings{ChatId: 1, BaseCurrency: "SGD"}))
+	assert.Error(t, s.Create(ctx, &schemas.ChatSettings{ChatId: 1}))
+
+	require.NoError(t, s.Update(ctx, &schemas.ChatSettings{ChatId: 1, BaseCurrency: "MYR"}))
+	got, _ = s.Get(ctx, 1)
+	assert.Equal(t, "MYR", got.Base())
+
+	require.NoError(t, s.Delete(ctx, 1))
+	assert.Error(t, s.Update(ctx, &schemas.ChatSettings{ChatId: 1}))
+}
+
+func TestMemoryNotificationStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryNotificationStore()
+	start := time.Date(2026, 2, 17, 9, 0, 0, 0, time.UTC)
+	for i, currency := range []string{"USD", "EUR", "USD"} {
+		require.NoError(t, s.Create(ctx, &schemas.Notification{ChatID: 1, BaseCurrency: "SGD", Currency: currency, SentAt: start.Add(time.Duration(i) * time.Hour)}))
+	}
+	require.NoError(t, s.Create(ctx, &schemas.Notification{ChatID: 2, BaseCurrency: "SGD", Currency: "USD", SentAt: start}))
+
+	all, err := s.ListByChat(ctx, 1, "", 0)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.True(t, all[0].SentAt.Equal(start.Add(2*time.Hour)))
+
+	usd, err := s.ListByChat(ctx, 1, "USD", 1)
+	require.NoError(t, err)
+	require.Len(t, usd, 1)
+	assert.Equal(t, all[0].ID, usd[0].ID)
+}
+
+func TestSetChatBaseCurrency(t *testing.T) {
+	useMemoryStores(t)
+	ctx := context.Background()
+
+	base, err := GetChatBaseCurrency(ctx, 42)
+	require.NoError(t, err)
+	assert.Equal(t, "SGD", base)
+
+	_, existed, err := InsertChatSettingsIfNotPresent(ctx, 42)
+	require.NoError(t, err)
+	assert.False(t, existed)
+	_, existed, err = InsertChatSettingsIfNotPresent(ctx, 42)
+	require.NoError(t, err)
+	assert.True(t, existed)
+
+	_, err = SetChatBaseCurrency(ctx, 42, "MYR")
+	require.NoError(t, err)
+	base, err = GetChatBaseCurrency(ctx, 42)
+	require.NoError(t, err)
+	assert.Equal(t, "MYR", base)
+}
+
+func TestAddSubscription(t *testing.T) {
+	useMemoryStores(t)
+	ctx := context.Background()
+	above, higher := 1.4, 1.45
+
+	first, existed, err := AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above})
+	require.NoError(t, err)
+	assert.False(t, existed)
+	assert.True(t, first.Enabled)
+
+	second, existed, err := AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &higher})
+	require.NoError(t, err)
+	assert.False(t, existed)
+	assert.NotEqual(t, first.ID, second.ID)
+
+	duplicate, existed, err := AddSubscription(ctx, &schemas.CurrencySubscription{ChatID: 1, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above})
+	require.NoError(t, err)
+	assert.True(t, existed)
+	assert.Equal(t, first.ID, duplicate.ID)
+
+	subscriptions, err := ListPairSubscriptions(ctx, 1, "SGD", "USD")
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 2)
+	assert.Less(t, subscriptions[0].ID, subscriptions[1].ID, "alerts on the same pair are listed by ID")
+}
+
+func TestFindSubscription(t *testing.T) {
+	useMemoryStores(t)
+	ctx := context.Background()
+	Subscriptions = &MemorySubscriptionStore{subscriptions: []schemas.CurrencySubscription{
+		{ID: "1a2b3c4d-aaaa", ChatID: 1, BaseCurrency: "SGD", Currency: "USD", Enabled: true},
+		{ID: "1a2b9999-bbbb", ChatID: 1, BaseCurrency: "SGD", Currency: "EUR", Enabled: true},
+		{ID: "ffff0000-cccc", ChatID: 2, BaseCurrency: "SGD", Currency: "USD", Enabled: true},
+	}}
+
+	sub, err := FindSubscription(ctx, 1, "1A2B3C")
+	require.NoError(t, err)
+	assert.Equal(t, "1a2b3c4d-aaaa", sub.ID)
+
+	_, err = FindSubscription(ctx, 1, "1a2b")
+	assert.ErrorIs(t, err, ErrAmbiguousAlertID)
+	_, err = FindSubscription(ctx, 1, "ffff0000")
+	assert.ErrorIs(t, err, ErrAlertNotFound)
+	_, err = FindSubscription(ctx, 1, "1a2")
+	assert.ErrorIs(t, err, ErrAlertNotFound)
+}
+
+func TestForEachActivePage(t *testing.T) {
+	useMemoryStores(t)
+	ctx := context.Background()
+	for i := 0; i < 250; i++ {
+		require.NoError(t, Subscriptions.Create(ctx, &schemas.CurrencySubscription{ChatID: int64(i), BaseCurrency: "SGD", Currency: "USD", Enabled: i%10 != 0}))
+	}
+
+	seen := make(map[string]bool)
+	pages, err := ForEachActivePage(ctx, 100, func(page []schemas.CurrencySubscription) error {
+		for _, sub := range page {
+			assert.False(t, seen[sub.ID], "subscription %s visited twice", sub.ID)
+			seen[sub.ID] = true
+			sub.Enabled = false
+			require.NoError(t, Subscriptions.Update(ctx, &sub))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, pages)
+	assert.Len(t, seen, 225)
+
+	pages, err = ForEachActivePage(ctx, 100, func([]schemas.CurrencySubscription) error { return nil })
+	require.NoError(t, err)
+	assert.Equal(t, 0, pages)
+}
@@ -0,0 +1,31 @@
+CREATE TABLE notifybot_chat_settings (
+    chat_id BIGINT PRIMARY KEY,
+    base_currency TEXT NOT NULL DEFAULT 'SGD',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE notifybot_currency_subscriptions (
+    id TEXT PRIMARY KEY,
+    chat_id BIGINT NOT NULL,
+    base_currency TEXT NOT NULL DEFAULT 'SGD',
+    currency TEXT NOT NULL,
+    threshold_above DOUBLE PRECISION,
+    threshold_below DOUBLE PRECISION,
+    "interval" DOUBLE PRECISION,
+    last_notified_rate DOUBLE PRECISION NOT NULL DEFAULT 0,
+    last_notification_time TIMESTAMPTZ,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE INDEX notifybot_currency_subscriptions_chat_id ON notifybot_currency_subscriptions (chat_id);
+
+CREATE TABLE notifybot_exchange_rates (
+    id BIGSERIAL PRIMARY KEY,
+    source TEXT NOT NULL,
+    base TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    date TEXT NOT NULL,
+    rate DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX notifybot_exchange_rates_lookup ON notifybot_exchange_rates (base, currency, date);
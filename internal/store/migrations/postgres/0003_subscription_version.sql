@@ -0,0 +1 @@
+ALTER TABLE notifybot_currency_subscriptions ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
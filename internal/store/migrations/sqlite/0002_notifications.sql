@@ -0,0 +1,16 @@
+CREATE TABLE notifybot_notifications (
+    id TEXT PRIMARY KEY,
+    subscription_id TEXT NOT NULL,
+    chat_id BIGINT NOT NULL,
+    base_currency TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    rate DOUBLE PRECISION NOT NULL,
+    "trigger" TEXT NOT NULL,
+    data_date TEXT NOT NULL DEFAULT '',
+    message_id BIGINT NOT NULL DEFAULT 0,
+    status TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    sent_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX notifybot_notifications_chat_sent_at ON notifybot_notifications (chat_id, sent_at);
@@ -0,0 +1,4 @@
+ALTER TABLE notifybot_currency_subscriptions ADD COLUMN threshold_above_percent DOUBLE PRECISION;
+ALTER TABLE notifybot_currency_subscriptions ADD COLUMN threshold_below_percent DOUBLE PRECISION;
+ALTER TABLE notifybot_currency_subscriptions ADD COLUMN interval_percent DOUBLE PRECISION;
+ALTER TABLE notifybot_currency_subscriptions ADD COLUMN reference_rate DOUBLE PRECISION NOT NULL DEFAULT 0;
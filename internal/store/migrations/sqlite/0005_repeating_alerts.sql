@@ -0,0 +1,4 @@
+ALTER TABLE notifybot_currency_subscriptions ADD COLUMN "repeat" BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE notifybot_currency_subscriptions ADD COLUMN hysteresis DOUBLE PRECISION;
+ALTER TABLE notifybot_currency_subscriptions ADD COLUMN hysteresis_percent DOUBLE PRECISION;
+ALTER TABLE notifybot_currency_subscriptions ADD COLUMN triggered TEXT NOT NULL DEFAULT '';
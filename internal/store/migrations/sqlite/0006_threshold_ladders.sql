@@ -0,0 +1,2 @@
+ALTER TABLE notifybot_currency_subscriptions ADD COLUMN ladder TEXT;
+ALTER TABLE notifybot_currency_subscriptions ADD COLUMN ladder_direction TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE notifybot_currency_subscriptions ADD COLUMN indicator TEXT;
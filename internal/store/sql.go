@@ -0,0 +1,501 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	log "github.com/sirupsen/logrus"
+	_ "modernc.org/sqlite"
+)
+
+//go:embed migrations
+var migrations embed.FS
+
+type SQLStore struct {
+	db      *sql.DB
+	dialect string
+}
+
+type SQLSubscriptionStore struct{ *SQLStore }
+
+type SQLChatSettingsStore struct{ *SQLStore }
+
+type SQLRateHistory struct{ *SQLStore }
+
+type SQLNotificationStore struct{ *SQLStore }
+
+func OpenSQLStore(ctx context.Context, dialect, dsn string) (*SQLStore, error) {
+	var driver string
+	switch dialect {
+	case BackendSQLite:
+		driver = "sqlite"
+	case BackendPostgres:
+		driver = "pgx"
+	default:
+		return nil, fmt.Errorf("unknown sql dialect: %s", dialect)
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if dialect == BackendSQLite {
+		db.SetMaxOpenConns(1)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	s := &SQLStore{db: db, dialect: dialect}
+	if err := s.Migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLStore) Subscriptions() SQLSubscriptionStore {
+	return SQLSubscriptionStore{s}
+}
+
+func (s *SQLStore) ChatSettings() SQLChatSettingsStore {
+	return SQLChatSettingsStore{s}
+}
+
+func (s *SQLStore) RateHistory() SQLRateHistory {
+	return SQLRateHistory{s}
+}
+
+func (s *SQLStore) Notifications() SQLNotificationStore {
+	return SQLNotificationStore{s}
+}
+
+// rebind rewrites ? placeholders into the $n form postgres expects.
+func (s *SQLStore) rebind(query string) string {
+	if s.dialect != BackendPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (s *SQLStore) Migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS notifybot_schema_migrations (
+		version INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	)`); err != nil {
+		return err
+	}
+
+	var current int
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM notifybot_schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+
+	dir := path.Join("migrations", s.dialect)
+	entries, err := fs.ReadDir(migrations, dir)
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		version, err := strconv.Atoi(strings.SplitN(entry.Name(), "_", 2)[0])
+		if err != nil {
+			return fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+		if version <= current {
+			continue
+		}
+		script, err := migrations.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := s.applyMigration(ctx, version, string(script)); err != nil {
+			return fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+		log.Infof("Applied %s migration %s", s.dialect, entry.Name())
+	}
+	return nil
+}
+
+func (s *SQLStore) applyMigration(ctx context.Context, version int, script string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range strings.Split(script, ";") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, s.rebind(`INSERT INTO notifybot_schema_migrations (version, applied_at) VALUES (?, ?)`), version, time.Now().UTC()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const subscriptionColumns = `id, chat_id, base_currency, currency, threshold_above, threshold_below, "interval", last_notified_rate, last_notification_time, enabled, version,
+	threshold_above_percent, threshold_below_percent, interval_percent, reference_rate,
+	"repeat", hysteresis, hysteresis_percent, triggered, ladder, ladder_direction, indicator`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSubscription(row rowScanner) (schemas.CurrencySubscription, error) {
+	var sub schemas.CurrencySubscription
+	var notifiedAt sql.NullTime
+	var ladder, indicator sql.NullString
+	err := row.Scan(&sub.ID, &sub.ChatID, &sub.BaseCurrency, &sub.Currency, &sub.ThresholdAbove, &sub.ThresholdBelow,
+		&sub.Interval, &sub.LastNotifiedRate, &notifiedAt, &sub.Enabled, &sub.Version,
+		&sub.ThresholdAbovePercent, &sub.ThresholdBelowPercent, &sub.IntervalPercent, &sub.ReferenceRate,
+		&sub.Repeat, &sub.Hysteresis, &sub.HysteresisPercent, &sub.Triggered, &ladder, &sub.LadderDirection, &indicator)
+	if err != nil {
+		return sub, err
+	}
+	if notifiedAt.Valid {
+		sub.LastNotificationTime = notifiedAt.Time
+	}
+	if ladder.Valid && ladder.String != "" {
+		if err = json.Unmarshal([]byte(ladder.String), &sub.Ladder); err != nil {
+			return sub, err
+		}
+	}
+	if indicator.Valid && indicator.String != "" {
+		err = json.Unmarshal([]byte(indicator.String), &sub.Indicator)
+	}
+	return sub, err
+}
+
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
+
+// ladderJSON stores a ladder as a JSON array, or NULL when there is none.
+func ladderJSON(rungs []float64) (any, error) {
+	if len(rungs) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(rungs)
+	return string(data), err
+}
+
+// indicatorJSON stores an indicator alert as a JSON object, or NULL when
+// there is none.
+func indicatorJSON(indicator *schemas.IndicatorAlert) (any, error) {
+	if indicator == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(indicator)
+	return string(data), err
+}
+
+func (s SQLSubscriptionStore) querySubscriptions(ctx context.Context, where string, args ...any) ([]schemas.CurrencySubscription, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT `+subscriptionColumns+` FROM notifybot_currency_subscriptions WHERE `+where), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []schemas.CurrencySubscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	return subscriptions, rows.Err()
+}
+
+func (s SQLSubscriptionStore) Create(ctx context.Context, sub *schemas.CurrencySubscription) error {
+	ladder, err := ladderJSON(sub.Ladder)
+	if err != nil {
+		return err
+	}
+	indicator, err := indicatorJSON(sub.Indicator)
+	if err != nil {
+		return err
+	}
+	id := newID()
+	_, err = s.db.ExecContext(ctx, s.rebind(`INSERT INTO notifybot_currency_subscriptions (`+subscriptionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		id, sub.ChatID, sub.Base(), sub.Currency, sub.ThresholdAbove, sub.ThresholdBelow, sub.Interval,
+		sub.LastNotifiedRate, nullTime(sub.LastNotificationTime), sub.Enabled, sub.Version,
+		sub.ThresholdAbovePercent, sub.ThresholdBelowPercent, sub.IntervalPercent, sub.ReferenceRate,
+		sub.Repeat, sub.Hysteresis, sub.HysteresisPercent, sub.Triggered, ladder, sub.LadderDirection, indicator)
+	if err != nil {
+		return fmt.Errorf("error creating subscription: %w", err)
+	}
+	sub.ID = id
+	return nil
+}
+
+func (s SQLSubscriptionStore) Update(ctx context.Context, sub *schemas.CurrencySubscription) error {
+	if sub.ID == "" {
+		return fmt.Errorf("cannot update subscription without ID")
+	}
+	ladder, err := ladderJSON(sub.Ladder)
+	if err != nil {
+		return err
+	}
+	indicator, err := indicatorJSON(sub.Indicator)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.ExecContext(ctx, s.rebind(`UPDATE notifybot_currency_subscriptions SET
+		base_currency = ?, currency = ?, threshold_above = ?, threshold_below = ?, "interval" = ?,
+		last_notified_rate = ?, last_notification_time = ?, enabled = ?, version = version + 1,
+		threshold_above_percent = ?, threshold_below_percent = ?, interval_percent = ?, reference_rate = ?,
+		"repeat" = ?, hysteresis = ?, hysteresis_percent = ?, triggered = ?, ladder = ?, ladder_direction = ?, indicator = ?
+		WHERE id = ? AND version = ?`),
+		sub.Base(), sub.Currency, sub.ThresholdAbove, sub.ThresholdBelow, sub.Interval,
+		sub.LastNotifiedRate, nullTime(sub.LastNotificationTime), sub.Enabled,
+		sub.ThresholdAbovePercent, sub.ThresholdBelowPercent, sub.IntervalPercent, sub.ReferenceRate,
+		sub.Repeat, sub.Hysteresis, sub.HysteresisPercent, sub.Triggered, ladder, sub.LadderDirection, indicator,
+		sub.ID, sub.Version)
+	if err != nil {
+		return fmt.Errorf("error updating subscription: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return s.updateConflict(ctx, sub.ID)
+	}
+	sub.Version++
+	return nil
+}
+
+// updateConflict tells apart the two reasons a versioned update can match no
+// rows.
+func (s SQLSubscriptionStore) updateConflict(ctx context.Context, id string) error {
+	current, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return fmt.Errorf("%w: %s", ErrAlertNotFound, id)
+	}
+	return fmt.Errorf("%w: %s", ErrVersionConflict, id)
+}
+
+func (s SQLSubscriptionStore) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("cannot delete subscription without ID")
+	}
+	res, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM notifybot_currency_subscriptions WHERE id = ?`), id)
+	if err != nil {
+		return fmt.Errorf("error deleting subscription: %w", err)
+	}
+	return expectRow(res, "subscription", id)
+}
+
+func (s SQLSubscriptionStore) Get(ctx context.Context, id string) (*schemas.CurrencySubscription, error) {
+	subscriptions, err := s.querySubscriptions(ctx, `id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(subscriptions) == 0 {
+		return nil, nil
+	}
+	return &subscriptions[0], nil
+}
+
+func (s SQLSubscriptionStore) ListByChat(ctx context.Context, chatID int64) ([]schemas.CurrencySubscription, error) {
+	return s.querySubscriptions(ctx, `chat_id = ? ORDER BY base_currency, currency, id`, chatID)
+}
+
+func (s SQLSubscriptionStore) ListActivePage(ctx context.Context, afterID string, limit int) ([]schemas.CurrencySubscription, error) {
+	return s.querySubscriptions(ctx, `enabled = ? AND id > ? ORDER BY id LIMIT ?`, true, afterID, limit)
+}
+
+func expectRow(res sql.Result, kind, id string) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%s not found: %s", kind, id)
+	}
+	return nil
+}
+
+func (s SQLChatSettingsStore) Create(ctx context.Context, settings *schemas.ChatSettings) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`INSERT INTO notifybot_chat_settings (chat_id, base_currency, created_at) VALUES (?, ?, ?)`),
+		settings.ChatId, settings.Base(), time.Time(settings.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("error inserting chat settings: %w", err)
+	}
+	return nil
+}
+
+func (s SQLChatSettingsStore) Update(ctx context.Context, settings *schemas.ChatSettings) error {
+	res, err := s.db.ExecContext(ctx, s.rebind(`UPDATE notifybot_chat_settings SET base_currency = ? WHERE chat_id = ?`),
+		settings.Base(), settings.ChatId)
+	if err != nil {
+		return fmt.Errorf("error updating chat settings: %w", err)
+	}
+	return expectRow(res, "chat settings", strconv.FormatInt(settings.ChatId, 10))
+}
+
+func (s SQLChatSettingsStore) Delete(ctx context.Context, chatID int64) error {
+	res, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM notifybot_chat_settings WHERE chat_id = ?`), chatID)
+	if err != nil {
+		return fmt.Errorf("error deleting chat settings: %w", err)
+	}
+	return expectRow(res, "chat settings", strconv.FormatInt(chatID, 10))
+}
+
+func (s SQLChatSettingsStore) Get(ctx context.Context, chatID int64) (*schemas.ChatSettings, error) {
+	var settings schemas.ChatSettings
+	var createdAt time.Time
+	err := s.db.QueryRowContext(ctx, s.rebind(`SELECT chat_id, base_currency, created_at FROM notifybot_chat_settings WHERE chat_id = ?`), chatID).
+		Scan(&settings.ChatId, &settings.BaseCurrency, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	settings.CreatedAt = schemas.DatetimeWithoutTimezone(createdAt)
+	return &settings, nil
+}
+
+func (s SQLRateHistory) queryStoredRates(where string, args ...any) ([]schemas.StoredRate, error) {
+	rows, err := s.db.Query(s.rebind(`SELECT id, source, base, currency, date, rate FROM notifybot_exchange_rates WHERE `+where+` ORDER BY date`), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []schemas.StoredRate
+	for rows.Next() {
+		var r schemas.StoredRate
+		if err := rows.Scan(&r.ID, &r.Source, &r.Base, &r.Currency, &r.Date, &r.Rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, r)
+	}
+	return rates, rows.Err()
+}
+
+func (s SQLRateHistory) GetStoredRates(base, currency string, start, end time.Time) ([]schemas.HistoricalRate, error) {
+	stored, err := s.queryStoredRates(`base = ? AND currency = ? AND date BETWEEN ? AND ?`,
+		base, currency, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	rates := make([]schemas.HistoricalRate, 0, len(stored))
+	for _, r := range stored {
+		rate, err := r.ToHistoricalRate()
+		if err != nil {
+			continue
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+func (s SQLRateHistory) GetStoredRatesOnDate(base, date string) ([]schemas.StoredRate, error) {
+	return s.queryStoredRates(`base = ? AND date = ?`, base, date)
+}
+
+func (s SQLRateHistory) InsertStoredRates(rates []schemas.StoredRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(s.rebind(`INSERT INTO notifybot_exchange_rates (source, base, currency, date, rate) VALUES (?, ?, ?, ?, ?)`))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, r := range rates {
+		if _, err := stmt.Exec(r.Source, r.Base, r.Currency, r.Date, r.Rate); err != nil {
+			return fmt.Errorf("error inserting stored rates: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+const notificationColumns = `id, subscription_id, chat_id, base_currency, currency, rate, "trigger", data_date, message_id, status, error, sent_at`
+
+func (s SQLNotificationStore) Create(ctx context.Context, n *schemas.Notification) error {
+	id := newID()
+	_, err := s.db.ExecContext(ctx, s.rebind(`INSERT INTO notifybot_notifications (`+notificationColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		id, n.SubscriptionID, n.ChatID, n.BaseCurrency, n.Currency, n.Rate, n.Trigger, n.DataDate, n.MessageID, n.Status, n.Error, n.SentAt.UTC())
+	if err != nil {
+		return fmt.Errorf("error creating notification: %w", err)
+	}
+	n.ID = id
+	return nil
+}
+
+func (s SQLNotificationStore) ListByChat(ctx context.Context, chatID int64, currency string, limit int) ([]schemas.Notification, error) {
+	where, args := `chat_id = ?`, []any{chatID}
+	if currency != "" {
+		where += ` AND currency = ?`
+		args = append(args, currency)
+	}
+	query := `SELECT ` + notificationColumns + ` FROM notifybot_notifications WHERE ` + where + ` ORDER BY sent_at DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []schemas.Notification
+	for rows.Next() {
+		var n schemas.Notification
+		if err := rows.Scan(&n.ID, &n.SubscriptionID, &n.ChatID, &n.BaseCurrency, &n.Currency, &n.Rate, &n.Trigger,
+			&n.DataDate, &n.MessageID, &n.Status, &n.Error, &n.SentAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
@@ -0,0 +1,227 @@
+package store
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestSQLStores(t *testing.T) []*SQLStore {
+	ctx := context.Background()
+	sqlite, err := OpenSQLStore(ctx, BackendSQLite, filepath.Join(t.TempDir(), "notifybot.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlite.Close() })
+	stores := []*SQLStore{sqlite}
+
+	if dsn := os.Getenv("NOTIFYBOT_TEST_POSTGRES_URL"); dsn != "" {
+		postgres, err := OpenSQLStore(ctx, BackendPostgres, dsn)
+		require.NoError(t, err)
+		for _, table := range []string{"notifybot_currency_subscriptions", "notifybot_chat_settings", "notifybot_exchange_rates"} {
+			_, err := postgres.db.Exec("DELETE FROM " + table)
+			require.NoError(t, err)
+		}
+		t.Cleanup(func() { postgres.Close() })
+		stores = append(stores, postgres)
+	}
+	return stores
+}
+
+func TestSQLStore_Rebind(t *testing.T) {
+	query := `SELECT * FROM t WHERE a = ? AND b = ?`
+	assert.Equal(t, query, (&SQLStore{dialect: BackendSQLite}).rebind(query))
+	assert.Equal(t, `SELECT * FROM t WHERE a = $1 AND b = $2`, (&SQLStore{dialect: BackendPostgres}).rebind(query))
+}
+
+func TestSQLStore_MigrationsAreIdempotent(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "notifybot.db")
+	scripts, err := fs.ReadDir(migrations, "migrations/sqlite")
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		s, err := OpenSQLStore(ctx, BackendSQLite, path)
+		require.NoError(t, err)
+		var count int
+		require.NoError(t, s.db.QueryRow(`SELECT COUNT(*) FROM notifybot_schema_migrations`).Scan(&count))
+		assert.Equal(t, len(scripts), count)
+		s.Close()
+	}
+
+	_, err = OpenSQLStore(ctx, "mysql", "")
+	assert.Error(t, err)
+}
+
+func TestSQLSubscriptionStore(t *testing.T) {
+	ctx := context.Background()
+	for _, db := range openTestSQLStores(t) {
+		t.Run(db.dialect, func(t *testing.T) {
+			s := db.Subscriptions()
+			above, belowPercent := 1.4, -3.0
+
+			sub := &schemas.CurrencySubscription{ChatID: -1001234567890, BaseCurrency: "SGD", Currency: "USD", ThresholdAbove: &above,
+				ThresholdBelowPercent: &belowPercent, ReferenceRate: 1.35, Enabled: true}
+			require.NoError(t, s.Create(ctx, sub))
+			require.NotEmpty(t, sub.ID)
+			require.NoError(t, s.Create(ctx, &schemas.CurrencySubscription{ChatID: -1001234567890, BaseCurrency: "SGD", Currency: "EUR"}))
+
+			got, err := s.Get(ctx, sub.ID)
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			assert.Equal(t, above, *got.ThresholdAbove)
+			assert.Nil(t, got.ThresholdBelow)
+			assert.Equal(t, belowPercent, *got.ThresholdBelowPercent)
+			assert.Nil(t, got.IntervalPercent)
+			assert.Nil(t, got.Ladder)
+			assert.Nil(t, got.Indicator)
+			assert.Equal(t, 1.35, got.ReferenceRate)
+			assert.True(t, got.LastNotificationTime.IsZero())
+
+			stale := *got
+			notifiedAt := time.Date(2026, 2, 20, 9, 30, 0, 0, time.UTC)
+			got.ThresholdAbove = nil
+			got.LastNotifiedRate = 1.41
+			got.LastNotificationTime = notifiedAt
+			got.Enabled = false
+			got.Repeat, got.Triggered = true, schemas.TriggerBelow
+			got.Ladder, got.LadderDirection = []float64{1.345, 1.35}, schemas.TriggerAbove
+			got.Indicator = &schemas.IndicatorAlert{Kind: schemas.IndicatorSMA, Fast: 20, Slow: 50, Direction: schemas.TriggerAbove, LastSignal: "2026-02-20"}
+			require.NoError(t, s.Update(ctx, got))
+
+			got, err = s.Get(ctx, sub.ID)
+			require.NoError(t, err)
+			assert.Nil(t, got.ThresholdAbove)
+			assert.Equal(t, 1.41, got.LastNotifiedRate)
+			assert.True(t, notifiedAt.Equal(got.LastNotificationTime))
+			assert.False(t, got.Enabled)
+			assert.True(t, got.Repeat)
+			assert.Equal(t, schemas.TriggerBelow, got.Triggered)
+			assert.Nil(t, got.Hysteresis)
+			assert.Equal(t, []float64{1.345, 1.35}, got.Ladder)
+			assert.Equal(t, schemas.TriggerAbove, got.LadderDirection)
+			assert.Equal(t, &schemas.IndicatorAlert{Kind: schemas.IndicatorSMA, Fast: 20, Slow: 50, Direction: schemas.TriggerAbove, LastSignal: "2026-02-20"}, got.Indicator)
+			assert.Equal(t, 1, got.Version)
+
+			stale.LastNotifiedRate = 1.5
+			assert.ErrorIs(t, s.Update(ctx, &stale), ErrVersionConflict)
+			got, err = s.Get(ctx, sub.ID)
+			require.NoError(t, err)
+			assert.Equal(t, 1.41, got.LastNotifiedRate)
+
+			byChat, err := s.ListByChat(ctx, -1001234567890)
+			require.NoError(t, err)
+			require.Len(t, byChat, 2)
+			assert.Equal(t, "EUR", byChat[0].Currency, "alerts are listed by pair")
+			active, err := s.ListActivePage(ctx, "", DefaultPageSize)
+			require.NoError(t, err)
+			assert.Empty(t, active)
+
+			require.NoError(t, s.Delete(ctx, sub.ID))
+			assert.Error(t, s.Delete(ctx, sub.ID))
+			assert.ErrorIs(t, s.Update(ctx, sub), ErrAlertNotFound)
+		})
+	}
+}
+
+func TestSQLSubscriptionStore_ListActivePage(t *testing.T) {
+	ctx := context.Background()
+	for _, db := range openTestSQLStores(t) {
+		t.Run(db.dialect, func(t *testing.T) {
+			s := db.Subscriptions()
+			for i := 0; i < 5; i++ {
+				require.NoError(t, s.Create(ctx, &schemas.CurrencySubscription{ChatID: int64(i), BaseCurrency: "SGD", Currency: "USD", Enabled: i != 2}))
+			}
+
+			first, err := s.ListActivePage(ctx, "", 2)
+			require.NoError(t, err)
+			require.Len(t, first, 2)
+			assert.Less(t, first[0].ID, first[1].ID)
+
+			rest, err := s.ListActivePage(ctx, first[1].ID, 10)
+			require.NoError(t, err)
+			assert.Len(t, rest, 2)
+			assert.Greater(t, rest[0].ID, first[1].ID)
+		})
+	}
+}
+
+func TestSQLChatSettingsStore(t *testing.T) {
+	ctx := context.Background()
+	for _, db := range openTestSQLStores(t) {
+		t.Run(db.dialect, func(t *testing.T) {
+			s := db.ChatSettings()
+
+			got, err := s.Get(ctx, 7)
+			require.NoError(t, err)
+			assert.Nil(t, got)
+
+			createdAt := schemas.DatetimeWithoutTimezone(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+			require.NoError(t, s.Create(ctx, &schemas.ChatSettings{ChatId: 7, BaseCurrency: "SGD", CreatedAt: createdAt}))
+			assert.Error(t, s.Create(ctx, &schemas.ChatSettings{ChatId: 7, CreatedAt: createdAt}))
+
+			require.NoError(t, s.Update(ctx, &schemas.ChatSettings{ChatId: 7, BaseCurrency: "MYR"}))
+			got, err = s.Get(ctx, 7)
+			require.NoError(t, err)
+			assert.Equal(t, "MYR", got.Base())
+			assert.True(t, time.Time(createdAt).Equal(time.Time(got.CreatedAt)))
+
+			require.NoError(t, s.Delete(ctx, 7))
+			assert.Error(t, s.Delete(ctx, 7))
+		})
+	}
+}
+
+func TestSQLRateHistory(t *testing.T) {
+	for _, db := range openTestSQLStores(t) {
+		t.Run(db.dialect, func(t *testing.T) {
+			s := db.RateHistory()
+			require.NoError(t, s.InsertStoredRates([]schemas.StoredRate{
+				{Source: "Frankfurter", Base: "SGD", Currency: "USD", Date: "2025-01-03", Rate: 1.36},
+				{Source: "Frankfurter", Base: "SGD", Currency: "USD", Date: "2025-01-02", Rate: 1.35},
+				{Source: "Frankfurter", Base: "SGD", Currency: "EUR", Date: "2025-01-02", Rate: 1.41},
+			}))
+
+			rates, err := s.GetStoredRates("SGD", "USD", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC))
+			require.NoError(t, err)
+			require.Len(t, rates, 2)
+			assert.Equal(t, 1.35, rates[0].Rate)
+
+			onDate, err := s.GetStoredRatesOnDate("SGD", "2025-01-02")
+			require.NoError(t, err)
+			assert.Len(t, onDate, 2)
+		})
+	}
+}
+
+func TestSQLNotificationStore(t *testing.T) {
+	ctx := context.Background()
+	for _, db := range openTestSQLStores(t) {
+		t.Run(db.dialect, func(t *testing.T) {
+			s := db.Notifications()
+			sentAt := time.Date(2026, 2, 17, 9, 0, 0, 0, time.UTC)
+
+			delivered := &schemas.Notification{SubscriptionID: "sub-1", ChatID: -1001234567890, BaseCurrency: "SGD", Currency: "USD",
+				Rate: 1.2950, Trigger: schemas.TriggerBelow, DataDate: "2026-02-16", MessageID: 42, Status: schemas.NotificationDelivered, SentAt: sentAt}
+			require.NoError(t, s.Create(ctx, delivered))
+			require.NotEmpty(t, delivered.ID)
+			require.NoError(t, s.Create(ctx, &schemas.Notification{SubscriptionID: "sub-2", ChatID: -1001234567890, BaseCurrency: "SGD", Currency: "EUR",
+				Rate: 1.45, Trigger: schemas.TriggerInterval, Status: schemas.NotificationFailed, Error: "Forbidden", SentAt: sentAt.Add(time.Hour)}))
+
+			all, err := s.ListByChat(ctx, -1001234567890, "", 10)
+			require.NoError(t, err)
+			require.Len(t, all, 2)
+			assert.Equal(t, "EUR", all[0].Currency)
+			assert.Equal(t, "Forbidden", all[0].Error)
+
+			usd, err := s.ListByChat(ctx, -1001234567890, "USD", 10)
+			require.NoError(t, err)
+			require.Len(t, usd, 1)
+			assert.Equal(t, *delivered, usd[0])
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
+)
+
+// SubscriptionStore.Update only writes sub when its Version still matches the
+// stored one, and increments Version on success. It returns ErrVersionConflict
+// when the subscription changed since sub was loaded, and ErrAlertNotFound
+// when it no longer exists.
+type SubscriptionStore interface {
+	Create(ctx context.Context, sub *schemas.CurrencySubscription) error
+	Update(ctx context.Context, sub *schemas.CurrencySubscription) error
+	Delete(ctx context.Context, id string) error
+	Get(ctx context.Context, id string) (*schemas.CurrencySubscription, error)
+	ListByChat(ctx context.Context, chatID int64) ([]schemas.CurrencySubscription, error)
+	ListActivePage(ctx context.Context, afterID string, limit int) ([]schemas.CurrencySubscription, error)
+}
+
+type ChatSettingsStore interface {
+	Create(ctx context.Context, settings *schemas.ChatSettings) error
+	Update(ctx context.Context, settings *schemas.ChatSettings) error
+	Delete(ctx context.Context, chatID int64) error
+	Get(ctx context.Context, chatID int64) (*schemas.ChatSettings, error)
+}
+
+// NotificationStore keeps a log of every alert the scheduler sent.
+// ListByChat returns the newest notifications first, optionally limited to
+// one currency.
+type NotificationStore interface {
+	Create(ctx context.Context, notification *schemas.Notification) error
+	ListByChat(ctx context.Context, chatID int64, currency string, limit int) ([]schemas.Notification, error)
+}
+
+const (
+	DefaultPageSize   = 100
+	MinIDPrefixLength = 4
+)
+
+var (
+	ErrAlertNotFound    = errors.New("no alert with that ID")
+	ErrAmbiguousAlertID = errors.New("more than one alert starts with that ID")
+	ErrVersionConflict  = errors.New("subscription was modified concurrently")
+)
+
+const (
+	BackendDirectus = "directus"
+	BackendMemory   = "memory"
+	BackendSQLite   = "sqlite"
+	BackendPostgres = "postgres"
+)
+
+var (
+	Subscriptions SubscriptionStore        = DirectusSubscriptionStore{}
+	ChatSettings  ChatSettingsStore        = DirectusChatSettingsStore{}
+	RateHistory   schemas.RateHistoryStore = DirectusRateHistory{}
+	Notifications NotificationStore        = DirectusNotificationStore{}
+)
+
+func Configure(backend string) error {
+	switch strings.ToLower(backend) {
+	case "", BackendDirectus:
+		Subscriptions = DirectusSubscriptionStore{}
+		ChatSettings = DirectusChatSettingsStore{}
+		RateHistory = DirectusRateHistory{}
+		Notifications = DirectusNotificationStore{}
+	case BackendMemory:
+		Subscriptions = NewMemorySubscriptionStore()
+		ChatSettings = NewMemoryChatSettingsStore()
+		RateHistory = NewMemoryRateHistory()
+		Notifications = NewMemoryNotificationStore()
+	case BackendSQLite, BackendPostgres:
+		db, err := OpenSQLStore(context.Background(), strings.ToLower(backend), utils.DatabaseURL)
+		if err != nil {
+			return err
+		}
+		Subscriptions = db.Subscriptions()
+		ChatSettings = db.ChatSettings()
+		RateHistory = db.RateHistory()
+		Notifications = db.Notifications()
+	default:
+		return fmt.Errorf("unknown storage backend: %s", backend)
+	}
+	return nil
+}
+
+// ForEachActivePage walks every enabled subscription in pages of pageSize,
+// ordered by ID, and returns the number of pages passed to fn. Paging by ID
+// rather than offset keeps the walk stable while fn updates subscriptions.
+func ForEachActivePage(ctx context.Context, pageSize int, fn func([]schemas.CurrencySubscription) error) (int, error) {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	pages := 0
+	afterID := ""
+	for {
+		page, err := Subscriptions.ListActivePage(ctx, afterID, pageSize)
+		if err != nil {
+			return pages, err
+		}
+		if len(page) == 0 {
+			return pages, nil
+		}
+		pages++
+		if err := fn(page); err != nil {
+			return pages, err
+		}
+		if len(page) < pageSize {
+			return pages, nil
+		}
+		afterID = page[len(page)-1].ID
+	}
+}
+
+func InsertChatSettingsIfNotPresent(ctx context.Context, chatID int64) (*schemas.ChatSettings, bool, error) {
+	chatSettings, err := ChatSettings.Get(ctx, chatID)
+	if err != nil {
+		return nil, false, err
+	}
+	if chatSettings != nil {
+		return chatSettings, true, nil
+	}
+
+	localTimezone, err := time.LoadLocation(utils.DEFAULT_TIMEZONE)
+	if err != nil {
+		panic(err)
+	}
+	chatSettings = &schemas.ChatSettings{
+		ChatId:       chatID,
+		BaseCurrency: utils.DEFAULT_BASE_CURRENCY,
+		CreatedAt:    schemas.DatetimeWithoutTimezone(time.Now().In(localTimezone)),
+	}
+	if err := ChatSettings.Create(ctx, chatSettings); err != nil {
+		return nil, false, err
+	}
+	return chatSettings, false, nil
+}
+
+func GetChatBaseCurrency(ctx context.Context, chatID int64) (string, error) {
+	chatSettings, err := ChatSettings.Get(ctx, chatID)
+	if err != nil {
+		return "", err
+	}
+	if chatSettings == nil {
+		return utils.DEFAULT_BASE_CURRENCY, nil
+	}
+	return chatSettings.Base(), nil
+}
+
+func SetChatBaseCurrency(ctx context.Context, chatID int64, base string) (*schemas.ChatSettings, error) {
+	chatSettings, _, err := InsertChatSettingsIfNotPresent(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	chatSettings.BaseCurrency = base
+	if err := ChatSettings.Update(ctx, chatSettings); err != nil {
+		return nil, err
+	}
+	return chatSettings, nil
+}
+
+// AddSubscription stores sub as a new alert. If the chat already has an
+// enabled alert with the same pair and conditions, that alert is returned
+// instead and existed is true.
+func AddSubscription(ctx context.Context, sub *schemas.CurrencySubscription) (*schemas.CurrencySubscription, bool, error) {
+	existing, err := Subscriptions.ListByChat(ctx, sub.ChatID)
+	if err != nil {
+		return nil, false, err
+	}
+	for i := range existing {
+		if existing[i].Enabled && existing[i].SameAlert(sub) {
+			return &existing[i], true, nil
+		}
+	}
+
+	sub.Enabled = true
+	if err := Subscriptions.Create(ctx, sub); err != nil {
+		return nil, false, err
+	}
+	return sub, false, nil
+}
+
+func ListPairSubscriptions(ctx context.Context, chatID int64, base, currency string) ([]schemas.CurrencySubscription, error) {
+	subscriptions, err := Subscriptions.ListByChat(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	var matching []schemas.CurrencySubscription
+	for _, sub := range subscriptions {
+		if sub.Base() == base && sub.Currency == currency {
+			matching = append(matching, sub)
+		}
+	}
+	return matching, nil
+}
+
+// FindSubscription resolves an alert ID, or a unique prefix of one, among the
+// alerts belonging to chatID.
+func FindSubscription(ctx context.Context, chatID int64, idPrefix string) (*schemas.CurrencySubscription, error) {
+	idPrefix = strings.ToLower(idPrefix)
+	if len(idPrefix) < MinIDPrefixLength {
+		return nil, fmt.Errorf("%w: %q is shorter than %d characters", ErrAlertNotFound, idPrefix, MinIDPrefixLength)
+	}
+	subscriptions, err := Subscriptions.ListByChat(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	var found *schemas.CurrencySubscription
+	for i := range subscriptions {
+		if !strings.HasPrefix(strings.ToLower(subscriptions[i].ID), idPrefix) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("%w: %q", ErrAmbiguousAlertID, idPrefix)
+		}
+		found = &subscriptions[i]
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: %q", ErrAlertNotFound, idPrefix)
+	}
+	return found, nil
+}
@@ -1,42 +1,62 @@
 package utils
 
 import (
-	"strings"
+	"time"
 )
 
 var (
-	LogLevel             string
-	DirectusHost         string
-	DirectusToken        string
-	BotToken             string
-	WhitelistedUsernames []string
+	LogLevel                string
+	StorageBackend          string
+	DatabaseURL             string
+	SubscriptionPageSize    int
+	DirectusHost            string
+	DirectusToken           string
+	DirectusRealtimeEnabled bool
+	BotToken                string
+	WhitelistedUsernames    []string
+	RateProviders           []string
+	RateCrossCheckProvider  string
+	RateCrossCheckTolerance float64
+	RateCrossCheckMode      string
+	RatePivotCurrencies     []string
+	RateCacheMaxTTL         time.Duration
+	FrankfurterAPIURL       string
+	MASAPIURL               string
+	MASResourceID           string
+	RateHistoryEnabled      bool
+	CurrencyAllowlist       []string
+	CurrencyExtras          []string
+	CurrencyRefreshInterval time.Duration
 )
 
-const HELP_MESSAGE string = `This bot notifies you on currency exchange rates against SGD. Rates are updated daily.
+const HELP_MESSAGE_TEMPLATE string = `This bot notifies you on currency exchange rates against your home currency (SGD by default). Rates are updated daily.
 
 Available Commands:
-/fx <currency> - Show current exchange rate
-/fx_chart <currency> [months] - Show historical chart (default: 12 months)
-/fx_subscribe <currency> -above <rate> - Notify when rate goes above threshold
-/fx_subscribe <currency> -below <rate> - Notify when rate goes below threshold
-/fx_interval <currency> <interval> - Notify every X SGD change
-/fx_list - List all your subscriptions
-/fx_unsubscribe <currency> - Remove subscription for currency
+/settings base <currency> - Set the home currency for this chat
+/fx <currency> [quote] - Show current exchange rate
+/fx_chart <currency> [quote] [months] - Show historical chart (default: 12 months)
+/fx_subscribe <currency> [quote] -above <rate|+N%%> - Notify when rate goes above threshold
+/fx_subscribe <currency> [quote] -below <rate|-N%%> - Notify when rate goes below threshold
+/fx_subscribe ... -repeat [band] - Re-arm the threshold after the rate moves back past the band
+/fx_interval <currency> [quote] <interval|N%%> - Notify every X (or X%%) change in quote currency
+/fx_ladder <currency> [quote] <first> <last> step <size> - Notify once for each rung the rate crosses
+/fx_indicator <currency> [quote] sma|ema <fast> <slow> above|below - Notify on a moving-average crossover
+/fx_indicator <currency> [quote] rsi <period> above|below <level> - Notify when RSI crosses a level
+/fx_indicator <currency> [quote] bollinger <period> <sigma> [above|below] - Notify when the rate leaves the Bollinger band
+/fx_list - List all your alerts with their IDs
+/fx_unsubscribe <id> [id...] - Remove alerts by ID
+/fx_unsubscribe <currency> [quote] - Remove every alert for currency pair
+/fx_history_alerts [currency] [count] - Show recent alerts sent to this chat
+/fx_export [json|csv] - Export this chat's alerts and settings as a file
+/fx_import - Reply to an exported file to import its alerts into this chat
+/currencies - List supported currencies with their names
+
+[quote] defaults to your home currency, e.g. /fx EUR USD shows EUR priced in USD.
 
 Supported Currencies:
-USD, EUR, GBP, JPY, MYR, HKD, AUD, KRW, TWD, IDR, THB, CNY, INR, PHP
+%s
 `
 
 const DEFAULT_TIMEZONE = "Asia/Singapore"
 
-var SupportedCurrencies = []string{"USD", "EUR", "GBP", "JPY", "MYR", "HKD", "AUD", "KRW", "TWD", "IDR", "THB", "CNY", "INR", "PHP"}
-
-func IsCurrencySupported(currency string) bool {
-	upperCurrency := strings.ToUpper(currency)
-	for _, c := range SupportedCurrencies {
-		if c == upperCurrency {
-			return true
-		}
-	}
-	return false
-}
+const DEFAULT_BASE_CURRENCY = "SGD"
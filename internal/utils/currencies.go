@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type Currency struct {
+	Code string
+	Name string
+}
+
+// DefaultCurrencies is served until the provider's currency list has loaded,
+// and kept if it never does.
+var DefaultCurrencies = []Currency{
+	{Code: "SGD", Name: "Singapore Dollar"},
+	{Code: "USD", Name: "US Dollar"},
+	{Code: "EUR", Name: "Euro"},
+	{Code: "GBP", Name: "British Pound"},
+	{Code: "JPY", Name: "Japanese Yen"},
+	{Code: "MYR", Name: "Malaysian Ringgit"},
+	{Code: "HKD", Name: "Hong Kong Dollar"},
+	{Code: "AUD", Name: "Australian Dollar"},
+	{Code: "KRW", Name: "Korean Won"},
+	{Code: "TWD", Name: "New Taiwan Dollar"},
+	{Code: "IDR", Name: "Indonesian Rupiah"},
+	{Code: "THB", Name: "Thai Baht"},
+	{Code: "CNY", Name: "Chinese Renminbi"},
+	{Code: "INR", Name: "Indian Rupee"},
+	{Code: "PHP", Name: "Philippine Peso"},
+}
+
+var (
+	currencyMu  sync.RWMutex
+	currencies  = DefaultCurrencies
+	currencyIdx = indexCurrencies(DefaultCurrencies)
+)
+
+func indexCurrencies(list []Currency) map[string]Currency {
+	idx := make(map[string]Currency, len(list))
+	for _, c := range list {
+		idx[c.Code] = c
+	}
+	return idx
+}
+
+// SetSupportedCurrencies replaces the catalogue. The home currency is listed
+// first, the rest alphabetically.
+func SetSupportedCurrencies(list []Currency) {
+	sorted := make([]Currency, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for _, c := range list {
+		c.Code = strings.ToUpper(strings.TrimSpace(c.Code))
+		if c.Code == "" || seen[c.Code] {
+			continue
+		}
+		seen[c.Code] = true
+		sorted = append(sorted, c)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Code == DEFAULT_BASE_CURRENCY || sorted[j].Code == DEFAULT_BASE_CURRENCY {
+			return sorted[i].Code == DEFAULT_BASE_CURRENCY
+		}
+		return sorted[i].Code < sorted[j].Code
+	})
+
+	currencyMu.Lock()
+	defer currencyMu.Unlock()
+	currencies = sorted
+	currencyIdx = indexCurrencies(sorted)
+}
+
+func SupportedCurrencies() []Currency {
+	currencyMu.RLock()
+	defer currencyMu.RUnlock()
+	return append([]Currency(nil), currencies...)
+}
+
+func SupportedCurrencyCodes() []string {
+	currencyMu.RLock()
+	defer currencyMu.RUnlock()
+	codes := make([]string, len(currencies))
+	for i, c := range currencies {
+		codes[i] = c.Code
+	}
+	return codes
+}
+
+func IsCurrencySupported(currency string) bool {
+	currencyMu.RLock()
+	defer currencyMu.RUnlock()
+	_, ok := currencyIdx[strings.ToUpper(currency)]
+	return ok
+}
+
+func CurrencyName(code string) string {
+	currencyMu.RLock()
+	defer currencyMu.RUnlock()
+	return currencyIdx[strings.ToUpper(code)].Name
+}
+
+func HelpMessage() string {
+	return fmt.Sprintf(HELP_MESSAGE_TEMPLATE, strings.Join(SupportedCurrencyCodes(), ", "))
+}
+
+func CurrencyListMessage() string {
+	var sb strings.Builder
+	sb.WriteString("💱 Supported Currencies\n\n")
+	for _, c := range SupportedCurrencies() {
+		if c.Name != "" {
+			sb.WriteString(fmt.Sprintf("%s - %s\n", c.Code, c.Name))
+		} else {
+			sb.WriteString(c.Code + "\n")
+		}
+	}
+	return sb.String()
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func FloatPtr(num float64) *float64 {
@@ -31,6 +32,18 @@ func LookupEnvStringArray(key string) []string {
 	return strings.Split(envVariable, ",")
 }
 
+// LookupEnvCurrencyCodes reads a comma-separated list of currency codes,
+// trimming and upper-casing each entry and skipping empty ones.
+func LookupEnvCurrencyCodes(key string) []string {
+	var codes []string
+	for _, entry := range LookupEnvStringArray(key) {
+		if code := strings.ToUpper(strings.TrimSpace(entry)); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
 func LookupEnvString(key string) string {
 	envVariable, exists := os.LookupEnv(key)
 	if !exists {
@@ -39,6 +52,14 @@ func LookupEnvString(key string) string {
 	return envVariable
 }
 
+func LookupEnvStringOrDefault(key string, defaultVal string) string {
+	envVariable, exists := os.LookupEnv(key)
+	if !exists || envVariable == "" {
+		return defaultVal
+	}
+	return envVariable
+}
+
 func LookupEnvInt(key string) int {
 	envVariable, exists := os.LookupEnv(key)
 	if !exists {
@@ -61,3 +82,36 @@ func ParseFloatOrDefault(s string, defaultVal float64) float64 {
 	}
 	return val
 }
+
+func ParseIntOrDefault(s string, defaultVal int) int {
+	if s == "" {
+		return defaultVal
+	}
+	val, err := strconv.Atoi(s)
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
+func ParseDurationOrDefault(s string, defaultVal time.Duration) time.Duration {
+	if s == "" {
+		return defaultVal
+	}
+	val, err := time.ParseDuration(s)
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
+func ParseBoolOrDefault(s string, defaultVal bool) bool {
+	if s == "" {
+		return defaultVal
+	}
+	val, err := strconv.ParseBool(s)
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -57,6 +58,11 @@ func TestLookupEnvStringArray_NotSet(t *testing.T) {
 	assert.Empty(t, result)
 }
 
+func TestLookupEnvCurrencyCodes(t *testing.T) {
+	t.Setenv("TEST_CURRENCY_CODES", "SGD, eur,, usd ")
+	assert.Equal(t, []string{"SGD", "EUR", "USD"}, LookupEnvCurrencyCodes("TEST_CURRENCY_CODES"))
+}
+
 func TestLookupEnvStringArray_Set(t *testing.T) {
 	t.Setenv("TEST_ARRAY_VAR", "a,b,c")
 	result := LookupEnvStringArray("TEST_ARRAY_VAR")
@@ -64,6 +70,36 @@ func TestLookupEnvStringArray_Set(t *testing.T) {
 	assert.Equal(t, []string{"a", "b", "c"}, result)
 }
 
+func TestLookupEnvStringOrDefault(t *testing.T) {
+	t.Setenv("TEST_STRING_VAR", "frankfurter")
+	assert.Equal(t, "frankfurter", LookupEnvStringOrDefault("TEST_STRING_VAR", "default"))
+
+	t.Setenv("TEST_STRING_VAR", "")
+	assert.Equal(t, "default", LookupEnvStringOrDefault("TEST_STRING_VAR", "default"))
+	assert.Equal(t, "default", LookupEnvStringOrDefault("TEST_UNSET_STRING_VAR", "default"))
+}
+
+func TestParseDurationOrDefault(t *testing.T) {
+	assert.Equal(t, 30*time.Minute, ParseDurationOrDefault("30m", time.Hour))
+	assert.Equal(t, time.Duration(0), ParseDurationOrDefault("0", time.Hour))
+	assert.Equal(t, time.Hour, ParseDurationOrDefault("", time.Hour))
+	assert.Equal(t, time.Hour, ParseDurationOrDefault("soon", time.Hour))
+}
+
+func TestParseIntOrDefault(t *testing.T) {
+	assert.Equal(t, 5, ParseIntOrDefault("5", 3))
+	assert.Equal(t, 0, ParseIntOrDefault("0", 3))
+	assert.Equal(t, 3, ParseIntOrDefault("", 3))
+	assert.Equal(t, 3, ParseIntOrDefault("many", 3))
+}
+
+func TestParseBoolOrDefault(t *testing.T) {
+	assert.True(t, ParseBoolOrDefault("true", false))
+	assert.False(t, ParseBoolOrDefault("0", true))
+	assert.True(t, ParseBoolOrDefault("", true))
+	assert.False(t, ParseBoolOrDefault("maybe", false))
+}
+
 func TestIsCurrencySupported(t *testing.T) {
 	tests := []struct {
 		currency string
@@ -83,6 +119,7 @@ func TestIsCurrencySupported(t *testing.T) {
 		{"CNY", true},
 		{"INR", true},
 		{"PHP", true},
+		{"SGD", true},
 		{"usd", true},
 		{"eur", true},
 		{"Usd", true},
@@ -97,32 +134,58 @@ func TestIsCurrencySupported(t *testing.T) {
 	}
 }
 
-func TestSupportedCurrencies_ContainsAll(t *testing.T) {
-	expected := []string{"USD", "EUR", "GBP", "JPY", "MYR", "HKD", "AUD", "KRW", "TWD", "IDR", "THB", "CNY", "INR", "PHP"}
-	assert.ElementsMatch(t, SupportedCurrencies, expected)
+func TestSupportedCurrencies_DefaultCatalogue(t *testing.T) {
+	expected := []string{"SGD", "USD", "EUR", "GBP", "JPY", "MYR", "HKD", "AUD", "KRW", "TWD", "IDR", "THB", "CNY", "INR", "PHP"}
+	assert.ElementsMatch(t, SupportedCurrencyCodes(), expected)
+}
+
+func useCurrencies(t *testing.T, list []Currency) {
+	original := SupportedCurrencies()
+	SetSupportedCurrencies(list)
+	t.Cleanup(func() { SetSupportedCurrencies(original) })
+}
+
+func TestSetSupportedCurrencies(t *testing.T) {
+	useCurrencies(t, []Currency{
+		{Code: "usd", Name: "US Dollar"},
+		{Code: "CHF", Name: "Swiss Franc"},
+		{Code: "SGD", Name: "Singapore Dollar"},
+		{Code: "USD", Name: "Duplicate"},
+	})
+
+	assert.Equal(t, []string{"SGD", "CHF", "USD"}, SupportedCurrencyCodes())
+	assert.True(t, IsCurrencySupported("chf"))
+	assert.False(t, IsCurrencySupported("JPY"))
+	assert.Equal(t, "US Dollar", CurrencyName("USD"))
+	assert.Contains(t, HelpMessage(), "SGD, CHF, USD")
+	assert.Contains(t, CurrencyListMessage(), "CHF - Swiss Franc")
 }
 
 func TestHELPMessage_ContainsAllCommands(t *testing.T) {
 	commands := []string{
+		"/settings",
 		"/fx",
 		"/fx_chart",
 		"/fx_subscribe",
 		"/fx_interval",
+		"/fx_ladder",
+		"/fx_indicator",
 		"/fx_list",
 		"/fx_unsubscribe",
+		"/currencies",
 	}
 
 	for _, cmd := range commands {
-		assert.Contains(t, HELP_MESSAGE, cmd, "HELP_MESSAGE should contain %s", cmd)
+		assert.Contains(t, HelpMessage(), cmd, "HelpMessage should contain %s", cmd)
 	}
 }
 
 func TestHELPMessage_ContainsAllCurrencies(t *testing.T) {
-	for _, currency := range SupportedCurrencies {
-		assert.Contains(t, HELP_MESSAGE, currency, "HELP_MESSAGE should contain %s", currency)
+	for _, currency := range SupportedCurrencyCodes() {
+		assert.Contains(t, HelpMessage(), currency, "HelpMessage should contain %s", currency)
 	}
 }
 
 func TestHELPMessage_DescribesDataSource(t *testing.T) {
-	assert.Contains(t, HELP_MESSAGE, "daily")
+	assert.Contains(t, HelpMessage(), "daily")
 }
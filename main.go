@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"os"
+	"strings"
+	"time"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"github.com/Jason-CKY/telegram-notifybot/internal/core"
 	"github.com/Jason-CKY/telegram-notifybot/internal/handler"
+	"github.com/Jason-CKY/telegram-notifybot/internal/httpclient"
+	"github.com/Jason-CKY/telegram-notifybot/internal/schemas"
+	"github.com/Jason-CKY/telegram-notifybot/internal/store"
 	"github.com/Jason-CKY/telegram-notifybot/internal/utils"
 	"github.com/joho/godotenv"
 	log "github.com/sirupsen/logrus"
@@ -18,10 +27,45 @@ func main() {
 	}
 
 	utils.LogLevel = utils.LookupEnvString("LOG_LEVEL")
-	utils.DirectusHost = utils.LookupEnvString("DIRECTUS_HOST")
-	utils.DirectusToken = utils.LookupEnvString("DIRECTUS_TOKEN")
+	utils.StorageBackend = strings.ToLower(utils.LookupEnvStringOrDefault("STORAGE_BACKEND", store.BackendDirectus))
+	switch utils.StorageBackend {
+	case store.BackendDirectus:
+		utils.DirectusHost = utils.LookupEnvString("DIRECTUS_HOST")
+		utils.DirectusToken = utils.LookupEnvString("DIRECTUS_TOKEN")
+		utils.DirectusRealtimeEnabled = utils.ParseBoolOrDefault(utils.LookupEnvStringOrDefault("DIRECTUS_REALTIME_ENABLED", ""), true)
+	case store.BackendSQLite:
+		utils.DatabaseURL = utils.LookupEnvStringOrDefault("DATABASE_URL", "notifybot.db")
+	case store.BackendPostgres:
+		utils.DatabaseURL = utils.LookupEnvString("DATABASE_URL")
+	}
 	utils.BotToken = utils.LookupEnvString("TELEGRAM_BOT_TOKEN")
 	utils.WhitelistedUsernames = utils.LookupEnvStringArray("ALLOWED_USERNAMES")
+	utils.RateProviders = utils.LookupEnvStringArray("RATE_PROVIDER")
+	utils.RateCrossCheckProvider = utils.LookupEnvStringOrDefault("RATE_CROSSCHECK_PROVIDER", "")
+	utils.RateCrossCheckTolerance = utils.ParseFloatOrDefault(utils.LookupEnvStringOrDefault("RATE_CROSSCHECK_TOLERANCE", ""), 0.5)
+	utils.RateCrossCheckMode = strings.ToLower(strings.TrimSpace(utils.LookupEnvStringOrDefault("RATE_CROSSCHECK_MODE", core.CrossCheckModeFlag)))
+	utils.RateCacheMaxTTL = utils.ParseDurationOrDefault(utils.LookupEnvStringOrDefault("RATE_CACHE_MAX_TTL", ""), 3*time.Hour)
+	utils.RatePivotCurrencies = utils.LookupEnvCurrencyCodes("RATE_PIVOT_CURRENCIES")
+	if len(utils.RatePivotCurrencies) == 0 {
+		utils.RatePivotCurrencies = []string{utils.DEFAULT_BASE_CURRENCY, "EUR", "USD"}
+	}
+	utils.FrankfurterAPIURL = utils.LookupEnvStringOrDefault("FRANKFURTER_API_URL", schemas.FrankfurterAPIURL)
+	utils.MASAPIURL = utils.LookupEnvStringOrDefault("MAS_API_URL", schemas.MASAPIURL)
+	utils.MASResourceID = utils.LookupEnvStringOrDefault("MAS_RESOURCE_ID", schemas.MASDailyResourceID)
+	utils.RateHistoryEnabled = utils.ParseBoolOrDefault(utils.LookupEnvStringOrDefault("RATE_HISTORY_ENABLED", ""), false)
+	utils.CurrencyAllowlist = utils.LookupEnvStringArray("SUPPORTED_CURRENCIES")
+	utils.CurrencyExtras = utils.LookupEnvStringArray("EXTRA_CURRENCIES")
+	utils.SubscriptionPageSize = utils.ParseIntOrDefault(utils.LookupEnvStringOrDefault("SUBSCRIPTION_PAGE_SIZE", ""), store.DefaultPageSize)
+	utils.CurrencyRefreshInterval = utils.ParseDurationOrDefault(utils.LookupEnvStringOrDefault("CURRENCY_REFRESH_INTERVAL", ""), 24*time.Hour)
+
+	httpConfig := httpclient.DefaultConfig()
+	httpConfig.Timeout = utils.ParseDurationOrDefault(utils.LookupEnvStringOrDefault("HTTP_TIMEOUT", ""), httpConfig.Timeout)
+	httpConfig.MaxRetries = utils.ParseIntOrDefault(utils.LookupEnvStringOrDefault("HTTP_MAX_RETRIES", ""), httpConfig.MaxRetries)
+	httpConfig.BaseDelay = utils.ParseDurationOrDefault(utils.LookupEnvStringOrDefault("HTTP_RETRY_BASE_DELAY", ""), httpConfig.BaseDelay)
+	httpConfig.MaxDelay = utils.ParseDurationOrDefault(utils.LookupEnvStringOrDefault("HTTP_RETRY_MAX_DELAY", ""), httpConfig.MaxDelay)
+	httpConfig.BreakerThreshold = utils.ParseIntOrDefault(utils.LookupEnvStringOrDefault("HTTP_BREAKER_THRESHOLD", ""), httpConfig.BreakerThreshold)
+	httpConfig.BreakerCooldown = utils.ParseDurationOrDefault(utils.LookupEnvStringOrDefault("HTTP_BREAKER_COOLDOWN", ""), httpConfig.BreakerCooldown)
+	httpclient.Default = httpclient.New(httpConfig)
 
 	log.SetReportCaller(true)
 	log.SetFormatter(&log.TextFormatter{
@@ -31,6 +75,61 @@ func main() {
 	logLevel, _ := log.ParseLevel(utils.LogLevel)
 	log.SetLevel(logLevel)
 
+	if err := core.ValidateCrossCheckMode(utils.RateCrossCheckMode); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := store.Configure(utils.StorageBackend); err != nil {
+		log.Fatal(err)
+	}
+	log.Infof("Using %s storage backend", utils.StorageBackend)
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate()
+		return
+	}
+
+	if utils.StorageBackend == store.BackendDirectus {
+		if err := store.VerifyDirectusSchema(context.Background(), store.DirectusMigrations); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "backfill" {
+		runBackfill(os.Args[2:])
+		return
+	}
+
+	if utils.StorageBackend == store.BackendDirectus && utils.DirectusRealtimeEnabled {
+		store.StartDirectusSync(context.Background())
+	}
+
+	opts := core.RateProviderOptions{
+		Providers:        utils.RateProviders,
+		CrossCheck:       utils.RateCrossCheckProvider,
+		TolerancePercent: utils.RateCrossCheckTolerance,
+		Pivots:           utils.RatePivotCurrencies,
+		CacheTTL:         utils.RateCacheMaxTTL,
+	}
+	if utils.RateHistoryEnabled {
+		core.RateHistory = store.RateHistory
+		opts.History = core.RateHistory
+	}
+	provider, err := core.BuildRateProvider(opts)
+	if err != nil {
+		panic(err)
+	}
+	core.ActiveRateProvider = provider
+	log.Infof("Using %s as exchange rate provider", provider.Name())
+	if err := core.ValidatePivotCurrencies(provider, utils.RatePivotCurrencies); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := core.RefreshCurrencyCatalogue(); err != nil {
+		log.Errorf("Error loading supported currencies, using built-in list: %v", err)
+	}
+	go core.StartCurrencyRefresher(utils.CurrencyRefreshInterval)
+
 	log.Info("connecting to telegram bot")
 
 	bot, err := tgbotapi.NewBotAPI(utils.BotToken)
@@ -50,3 +149,54 @@ func main() {
 		handler.HandleUpdate(&update, bot)
 	}
 }
+
+func runMigrate() {
+	if utils.StorageBackend != store.BackendDirectus {
+		log.Infof("%s storage is migrated automatically at startup", utils.StorageBackend)
+		return
+	}
+	applied, err := store.MigrateDirectus(context.Background(), store.DirectusMigrations)
+	if err != nil {
+		log.Fatalf("Migration failed after applying %d migrations: %v", applied, err)
+	}
+	log.Infof("Directus schema up to date, applied %d migrations", applied)
+}
+
+func runBackfill(args []string) {
+	flags := flag.NewFlagSet("backfill", flag.ExitOnError)
+	from := flags.String("from", "2010-01-01", "first date to backfill (YYYY-MM-DD)")
+	to := flags.String("to", time.Now().Format("2006-01-02"), "last date to backfill (YYYY-MM-DD)")
+	base := flags.String("base", utils.DEFAULT_BASE_CURRENCY, "base currency to store rates against")
+	flags.Parse(args)
+
+	start, err := time.Parse("2006-01-02", *from)
+	if err != nil {
+		log.Fatalf("Invalid -from date: %v", err)
+	}
+	end, err := time.Parse("2006-01-02", *to)
+	if err != nil {
+		log.Fatalf("Invalid -to date: %v", err)
+	}
+
+	provider, err := core.BuildRateProvider(core.RateProviderOptions{
+		Providers: utils.RateProviders,
+		Pivots:    utils.RatePivotCurrencies,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	core.ActiveRateProvider = provider
+	if err := core.ValidatePivotCurrencies(provider, utils.RatePivotCurrencies); err != nil {
+		log.Fatal(err)
+	}
+	if err := core.RefreshCurrencyCatalogue(); err != nil {
+		log.Errorf("Error loading supported currencies, using built-in list: %v", err)
+	}
+
+	log.Infof("Backfilling %s rates from %s to %s using %s", *base, *from, *to, provider.Name())
+	inserted, err := core.BackfillRateHistory(provider, store.RateHistory, *base, utils.SupportedCurrencyCodes(), start, end)
+	if err != nil {
+		log.Fatalf("Backfill failed after storing %d rates: %v", inserted, err)
+	}
+	log.Infof("Backfill complete, stored %d rates", inserted)
+}